@@ -0,0 +1,10 @@
+// Package examples contains the example scripts (they are used for the new project scaffolding).
+package examples
+
+import "embed"
+
+//go:embed *.js
+var scripts embed.FS
+
+// Scripts returns the example scripts file system.
+func Scripts() embed.FS { return scripts }
@@ -6,11 +6,16 @@ require (
 	github.com/bmatcuk/doublestar/v4 v4.4.0
 	github.com/dop251/goja v0.0.0-20221118162653-d4bf6fde1b86
 	github.com/go-faker/faker/v4 v4.0.0-beta.4
+	github.com/gorilla/websocket v1.5.0
 	github.com/jedib0t/go-pretty/v6 v6.4.3
 	github.com/json-iterator/go v1.1.12
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.8.1
 	github.com/urfave/cli/v2 v2.23.7
+	golang.org/x/net v0.4.0
+	golang.org/x/term v0.3.0
+	golang.org/x/text v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -25,7 +30,5 @@ require (
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
-	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
-	golang.org/x/text v0.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.3.0 // indirect
 )
@@ -7,8 +7,13 @@ import (
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/urfave/cli/v2"
 
+	"github.com/tarampampam/poke/internal/cli/check"
 	"github.com/tarampampam/poke/internal/cli/dts"
+	"github.com/tarampampam/poke/internal/cli/initialize"
+	"github.com/tarampampam/poke/internal/cli/list"
+	"github.com/tarampampam/poke/internal/cli/repl"
 	"github.com/tarampampam/poke/internal/cli/run"
+	"github.com/tarampampam/poke/internal/config"
 	"github.com/tarampampam/poke/internal/env"
 	"github.com/tarampampam/poke/internal/js"
 	"github.com/tarampampam/poke/internal/log"
@@ -23,9 +28,12 @@ func NewApp(l interface {
 	const (
 		logLevelFlagName = "log-level"
 		verboseFlagName  = "verbose"
+		configFlagName   = "config"
 		defaultLogLevel  = log.InfoLevel
 	)
 
+	var cfg = make(config.Config) // will be loaded before the command running
+
 	return &cli.App{
 		Usage: "Poke files runner",
 		Before: func(c *cli.Context) error {
@@ -48,12 +56,30 @@ func NewApp(l interface {
 				l.SetLevel(log.DebugLevel)
 			}
 
+			// load the configuration file (missing file in the default locations is not an error)
+			loaded, path, err := config.Discover(c.String(configFlagName))
+			if err != nil {
+				return err
+			}
+
+			if path != "" {
+				l.Debug("Configuration file loaded", log.With("file", path))
+			}
+
+			for k, v := range loaded {
+				cfg[k] = v
+			}
+
 			return nil
 		},
 		Version: version.Version(),
 		Commands: []*cli.Command{
-			run.NewCommand(l),
+			run.NewCommand(l, cfg),
+			list.NewCommand(l, cfg),
+			check.NewCommand(l, cfg),
 			dts.NewCommand(js.DTS()),
+			initialize.NewCommand(l),
+			repl.NewCommand(l),
 		},
 		Flags: []cli.Flag{ // global flags
 			&cli.StringFlag{
@@ -66,6 +92,12 @@ func NewApp(l interface {
 				Name:  verboseFlagName,
 				Usage: "verbose output (set the logging level to debug)",
 			},
+			&cli.StringFlag{
+				Name:    configFlagName,
+				Aliases: []string{"c"},
+				Usage:   "path to the configuration file (" + strings.Join(config.Locations(), " or ") + " by default)",
+				EnvVars: []string{env.ConfigFile.String()},
+			},
 		},
 	}
 }
@@ -1,11 +1,16 @@
 package cli_test
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tarampampam/poke/internal/cli"
+	"github.com/tarampampam/poke/internal/env"
 	"github.com/tarampampam/poke/internal/log"
 )
 
@@ -14,3 +19,76 @@ func TestNewApp(t *testing.T) {
 
 	require.NotEmpty(t, app.Commands)
 }
+
+func TestNewApp_ConfigPrecedence(t *testing.T) {
+	var (
+		dir        = t.TempDir()
+		jsonConfig = filepath.Join(dir, "json.yaml")
+		textConfig = filepath.Join(dir, "text.yaml")
+	)
+
+	// the files are relative to the configuration file directory
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.js"), []byte(`test('foo', () => {})`), 0o600))
+	require.NoError(t, os.WriteFile(jsonConfig, []byte("list: {format: json, files: [a.js]}"), 0o600))
+	require.NoError(t, os.WriteFile(textConfig, []byte("list: {format: text, files: [a.js]}"), 0o600))
+
+	for name, tt := range map[string]struct {
+		giveEnv    string
+		giveArgs   []string
+		wantOutput string
+		wantError  string
+	}{
+		"config flag": {
+			giveArgs:   []string{"--config", jsonConfig, "list"},
+			wantOutput: `"file": "` + filepath.Join(dir, "a.js") + `"`,
+		},
+		"config env": {
+			giveEnv:    jsonConfig,
+			giveArgs:   []string{"list"},
+			wantOutput: `"file": "` + filepath.Join(dir, "a.js") + `"`,
+		},
+		"config flag over env": {
+			giveEnv:    jsonConfig,
+			giveArgs:   []string{"-c", textConfig, "list"},
+			wantOutput: "── " + filepath.Join(dir, "a.js") + "\n",
+		},
+		"command flag over config": {
+			giveArgs:   []string{"-c", jsonConfig, "list", "--format", "text"},
+			wantOutput: "── " + filepath.Join(dir, "a.js") + "\n",
+		},
+		"command arguments over config files": {
+			giveArgs:  []string{"-c", jsonConfig, "list", filepath.Join(dir, "*.ts")},
+			wantError: "no files found",
+		},
+		"missing config file": {
+			giveArgs:  []string{"-c", filepath.Join(dir, "missing.yaml"), "list"},
+			wantError: "no such file or directory",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			if tt.giveEnv != "" {
+				t.Setenv(env.ConfigFile.String(), tt.giveEnv)
+			}
+
+			var (
+				out bytes.Buffer
+				app = cli.NewApp(log.NewNop())
+			)
+
+			app.Writer = &out
+
+			err := app.Run(append([]string{"poke"}, tt.giveArgs...))
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tt.wantOutput)
+		})
+	}
+}
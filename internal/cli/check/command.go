@@ -0,0 +1,115 @@
+package check
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/files"
+	"github.com/tarampampam/poke/internal/js/checker"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+type command struct {
+	c *cli.Command
+}
+
+// NewCommand creates `check` command.
+func NewCommand(l log.Logger, cfg config.Config) *cli.Command {
+	const strictFlagName = "strict"
+
+	var cmd = command{}
+
+	cmd.c = &cli.Command{
+		Name:      "check",
+		ArgsUsage: "<files-or-directories...>",
+		Usage:     "Check the scripts for syntax errors and common mistakes (without running them)",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  strictFlagName,
+				Usage: "treat warnings as errors",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := cfg.Apply(c); err != nil {
+				return err
+			}
+
+			var patterns = c.Args().Slice()
+
+			if len(patterns) == 0 {
+				patterns = cfg.Files(c.Command.Name)
+			}
+
+			found, findingErr := files.Find(patterns...)
+			if findingErr != nil {
+				return findingErr
+			}
+
+			if len(found) == 0 {
+				return fmt.Errorf("no files found in %s", strings.Join(patterns, ", "))
+			}
+
+			l.Debug("Found files", log.With("files", found))
+
+			var errorsCount, warningsCount int
+
+			for _, filePath := range found {
+				script, readErr := os.ReadFile(filePath)
+				if readErr != nil {
+					return readErr
+				}
+
+				for _, issue := range checker.Check(filePath, string(script)) {
+					if issue.Severity == checker.SeverityError {
+						errorsCount++
+					} else {
+						warningsCount++
+					}
+
+					if _, err := fmt.Fprintln(c.App.Writer, cmd.format(filePath, issue)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if errorsCount > 0 || (warningsCount > 0 && c.Bool(strictFlagName)) {
+				return fmt.Errorf("found %d error(s) and %d warning(s)", errorsCount, warningsCount)
+			}
+
+			l.Success(
+				fmt.Sprintf("Checked %d file(s)", len(found)),
+				log.With("warnings", warningsCount),
+			)
+
+			return nil
+		},
+	}
+
+	return cmd.c
+}
+
+var ( //nolint:gochecknoglobals
+	colorFilePosition = text.Colors{text.Bold}
+	colorError        = text.Colors{text.FgRed}
+	colorWarning      = text.Colors{text.FgYellow}
+)
+
+// format formats the issue in the `file:line:col: severity: message` format.
+func (cmd *command) format(filePath string, issue checker.Issue) string {
+	var severity = colorWarning
+
+	if issue.Severity == checker.SeverityError {
+		severity = colorError
+	}
+
+	return fmt.Sprintf("%s %s %s",
+		colorFilePosition.Sprintf("%s:%d:%d:", filePath, issue.Line, issue.Column),
+		severity.Sprintf("%s:", issue.Severity),
+		issue.Message,
+	)
+}
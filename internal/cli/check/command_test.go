@@ -0,0 +1,78 @@
+package check_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/cli/check"
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+func TestCommand(t *testing.T) {
+	text.DisableColors()
+
+	for name, tt := range map[string]struct {
+		giveArgs   []string
+		giveScript string
+		wantOutput string
+		wantErr    string
+	}{
+		"no issues": {
+			giveScript: `test('foo', () => { mustBe.true(true) })`,
+		},
+		"warning": {
+			giveScript: "test('foo', () => {})\nfooBar()",
+			wantOutput: ":2:1: warning: unknown global fooBar",
+		},
+		"warning in the strict mode": {
+			giveArgs:   []string{"--strict"},
+			giveScript: "test('foo', () => {})\nfooBar()",
+			wantOutput: ":2:1: warning: unknown global fooBar",
+			wantErr:    "found 0 error(s) and 1 warning(s)",
+		},
+		"syntax error": {
+			giveScript: "const a = (\n",
+			wantOutput: ":2:1: error: ",
+			wantErr:    "found 1 error(s) and 0 warning(s)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "script.js")
+
+			require.NoError(t, os.WriteFile(path, []byte(tt.giveScript), 0o600))
+
+			var (
+				out bytes.Buffer
+				app = &cli.App{Writer: &out, Commands: []*cli.Command{check.NewCommand(log.NewNop(), config.Config{})}}
+			)
+
+			err := app.Run(append(append([]string{"poke", "check"}, tt.giveArgs...), path))
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantOutput != "" {
+				assert.Contains(t, out.String(), path+tt.wantOutput)
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
+
+func TestCommand_NoFiles(t *testing.T) {
+	var app = &cli.App{Commands: []*cli.Command{check.NewCommand(log.NewNop(), config.Config{})}}
+
+	assert.ErrorContains(t, app.Run([]string{"poke", "check", filepath.Join(t.TempDir(), "*.js")}), "no files found in")
+}
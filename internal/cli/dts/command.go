@@ -9,15 +9,18 @@ import (
 	"github.com/tarampampam/poke/internal/version"
 )
 
+// Render returns the 'global.d.ts' file content with the generated code header.
+func Render(content string) string {
+	return "// Code generated by the poke tool version v" + version.Version() + ". DO NOT EDIT.\n\n" + content
+}
+
 // NewCommand creates `dts` command.
 func NewCommand(content string) *cli.Command {
-	var header = "// Code generated by the poke tool version v" + version.Version() + ". DO NOT EDIT.\n\n"
-
 	return &cli.Command{
 		Name:  "dts",
 		Usage: "Print the 'global.d.ts' file",
 		Action: func(c *cli.Context) (err error) {
-			_, err = fmt.Fprint(os.Stdout, header, content)
+			_, err = fmt.Fprint(os.Stdout, Render(content))
 
 			return
 		},
@@ -0,0 +1,187 @@
+package initialize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/examples"
+	"github.com/tarampampam/poke/internal/cli/dts"
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/js"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+type command struct {
+	c *cli.Command
+}
+
+// NewCommand creates `init` command.
+func NewCommand(l log.Logger) *cli.Command {
+	const (
+		dirFlagName        = "dir"
+		typescriptFlagName = "typescript"
+		forceFlagName      = "force"
+	)
+
+	var cmd = command{}
+
+	cmd.c = &cli.Command{
+		Name:  "init",
+		Usage: "Create a new tests directory with the sample scripts, 'global.d.ts' and configuration files",
+		Description: "The '" + config.DefaultFileName + "' configuration file is created in the tests directory. It is " +
+			"loaded automatically from the '" + config.DefaultTestsDir + "' directory, use the --config flag for another one.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    dirFlagName,
+				Aliases: []string{"d"},
+				Usage:   "tests directory path",
+				Value:   config.DefaultTestsDir,
+			},
+			&cli.BoolFlag{
+				Name:  typescriptFlagName,
+				Usage: "create 'tsconfig.json' instead of 'jsconfig.json'",
+			},
+			&cli.BoolFlag{
+				Name:    forceFlagName,
+				Aliases: []string{"f"},
+				Usage:   "overwrite existing files",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var dir = filepath.Clean(c.String(dirFlagName))
+
+			toCreate, err := cmd.Files(dir, c.Bool(typescriptFlagName))
+			if err != nil {
+				return err
+			}
+
+			if !c.Bool(forceFlagName) {
+				var exists []string
+
+				for _, f := range toCreate {
+					if _, statErr := os.Stat(f.path); statErr == nil {
+						exists = append(exists, f.path)
+					}
+				}
+
+				if len(exists) > 0 {
+					return fmt.Errorf(
+						"refusing to overwrite existing files (use --%s to overwrite): %s",
+						forceFlagName,
+						strings.Join(exists, ", "),
+					)
+				}
+			}
+
+			if err = os.MkdirAll(dir, 0o755); err != nil { //nolint:gomnd
+				return err
+			}
+
+			for _, f := range toCreate {
+				if err = os.WriteFile(f.path, f.content, 0o644); err != nil { //nolint:gomnd,gosec
+					return err
+				}
+
+				l.Success("File created", log.With("file", f.path))
+			}
+
+			if dir != config.DefaultTestsDir { // the configuration file will not be found automatically
+				l.Info("Use the --config flag to load the configuration file",
+					log.With("file", filepath.Join(dir, config.DefaultFileName)),
+				)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd.c
+}
+
+type file struct {
+	path    string
+	content []byte
+}
+
+// Files returns the list of files that should be created.
+func (cmd *command) Files(dir string, typescript bool) ([]file, error) {
+	var (
+		samples = examples.Scripts()
+		result  = []file{
+			{path: filepath.Join(dir, "global.d.ts"), content: []byte(dts.Render(js.DTS()))},
+			cmd.jsConfig(dir, typescript),
+			cmd.pokeConfig(dir),
+		}
+	)
+
+	if err := fs.WalkDir(samples, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, readErr := samples.ReadFile(path)
+		if readErr != nil {
+			return readErr
+		}
+
+		result = append(result, file{path: filepath.Join(dir, filepath.FromSlash(path)), content: content})
+
+		return nil
+	}); err != nil {
+		return nil, errors.New("cannot read the sample scripts: " + err.Error())
+	}
+
+	return result, nil
+}
+
+// jsConfig returns the 'jsconfig.json' (or 'tsconfig.json') file, wired to the 'global.d.ts' file.
+func (cmd *command) jsConfig(dir string, typescript bool) file {
+	var name = "jsconfig.json"
+
+	if typescript {
+		name = "tsconfig.json"
+	}
+
+	return file{path: filepath.Join(dir, name), content: []byte(`{
+  "compilerOptions": {
+    "target": "ES2020",
+    "lib": ["ES2020"],
+    "allowJs": true,
+    "checkJs": true,
+    "noEmit": true
+  },
+  "include": ["global.d.ts", "**/*.js"]
+}
+`)}
+}
+
+// pokeConfig returns the starter configuration file, that is created in the tests directory (the files patterns are
+// relative to it).
+func (cmd *command) pokeConfig(dir string) file {
+	const pattern = "**/*.js"
+
+	return file{path: filepath.Join(dir, config.DefaultFileName), content: []byte(
+		`# Poke configuration file. Top-level keys are the command
+# names, nested keys are the command flag names (e.g. "max-script-exec-time" for the "--max-script-exec-time"
+# flag). Flags from the command line take precedence.
+
+run:
+  # the list of files to run when no files are passed as the command arguments (wildcards are supported, relative
+  # paths are resolved against this file directory)
+  files: ['` + pattern + `']
+  # maximum execution time of each script
+  max-script-exec-time: 60s
+
+list:
+  files: ['` + pattern + `']
+
+check:
+  files: ['` + pattern + `']
+`)}
+}
@@ -0,0 +1,50 @@
+package initialize_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/cli/initialize"
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+func run(args ...string) error {
+	var app = &cli.App{Commands: []*cli.Command{initialize.NewCommand(log.NewNop())}}
+
+	return app.Run(append([]string{"poke", "init"}, args...))
+}
+
+func TestCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	require.NoError(t, run())
+
+	for _, name := range []string{"global.d.ts", "jsconfig.json", config.DefaultFileName} {
+		assert.FileExists(t, filepath.Join(config.DefaultTestsDir, name))
+	}
+
+	assert.NoFileExists(t, config.DefaultFileName) // the configuration file is created in the tests directory
+
+	// the created configuration file is found, and the files patterns are relative to it
+	cfg, path, err := config.Discover("")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(config.DefaultTestsDir, config.DefaultFileName), path)
+	assert.Equal(t, []string{filepath.Join(config.DefaultTestsDir, "**", "*.js")}, cfg.Files("run"))
+
+	assert.ErrorContains(t, run(), "refusing to overwrite existing files (use --force to overwrite)")
+	assert.NoError(t, run("--force", "--typescript"))
+	assert.FileExists(t, filepath.Join(config.DefaultTestsDir, "tsconfig.json"))
+
+	require.NoError(t, run("--dir", "custom"))
+	assert.FileExists(t, filepath.Join("custom", config.DefaultFileName))
+}
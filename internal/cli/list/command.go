@@ -0,0 +1,261 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/list"
+	"github.com/jedib0t/go-pretty/v6/text"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/files"
+	"github.com/tarampampam/poke/internal/js"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+type command struct {
+	c *cli.Command
+}
+
+// fileTests is a tests tree of the single script file.
+type fileTests struct {
+	File    string        `json:"file"`
+	Tests   []js.TestNode `json:"tests"`
+	Error   string        `json:"error,omitempty"`
+	Warning string        `json:"warning,omitempty"` // the tests list may be incomplete
+}
+
+// NewCommand creates `list` command.
+func NewCommand(l log.Logger, cfg config.Config) *cli.Command { //nolint:funlen
+	const (
+		formatFlagName            = "format"
+		maxScriptExecTimeFlagName = "max-script-exec-time"
+	)
+
+	var cmd = command{}
+
+	cmd.c = &cli.Command{
+		Name:        "list",
+		ArgsUsage:   "<files-or-directories...>",
+		Aliases:     []string{"ls"},
+		Usage:       "List the tests without running them",
+		Description: "Scripts are evaluated without network access (requests get stub responses), tests are not executed",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    formatFlagName,
+				Aliases: []string{"f"},
+				Usage:   "output format (" + strings.Join([]string{formatText, formatJSON}, "|") + ")",
+				Value:   formatText,
+			},
+			&cli.DurationFlag{
+				Name:  maxScriptExecTimeFlagName,
+				Usage: "maximum evaluation time of each script, e.g. '10s' or '1m'",
+				Value: 60 * time.Second, //nolint:gomnd // default value
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := cfg.Apply(c); err != nil {
+				return err
+			}
+
+			var (
+				format   = c.String(formatFlagName)
+				patterns = c.Args().Slice()
+			)
+
+			if len(patterns) == 0 {
+				patterns = cfg.Files(c.Command.Name)
+			}
+
+			if format != formatText && format != formatJSON {
+				return fmt.Errorf("unsupported output format: %s", format)
+			}
+
+			found, findingErr := files.Find(patterns...)
+			if findingErr != nil {
+				return findingErr
+			}
+
+			if len(found) == 0 {
+				return fmt.Errorf("no files found in %s", strings.Join(patterns, ", "))
+			}
+
+			l.Debug("Found files", log.With("files", found))
+
+			var (
+				result    = make([]fileTests, 0, len(found))
+				hasErrors bool
+			)
+
+			for _, filePath := range found {
+				var item = fileTests{File: filePath, Tests: []js.TestNode{}}
+
+				tests, err := cmd.CollectTests(c.Context, filePath, c.Duration(maxScriptExecTimeFlagName))
+
+				switch {
+				case err == nil:
+					item.Tests = tests
+
+				case errors.Is(err, js.ErrTestsIncomplete):
+					item.Tests, item.Warning = tests, err.Error()
+
+					l.Warn("Tests list may be incomplete", log.With("file", filePath), log.With("error", err))
+
+				default:
+					hasErrors, item.Error = true, err.Error()
+
+					l.Error("Tests collecting failed", log.With("file", filePath), log.With("error", err))
+				}
+
+				result = append(result, item)
+			}
+
+			var writingErr error
+
+			switch format {
+			case formatJSON:
+				writingErr = cmd.writeJSON(c.App.Writer, result)
+			default:
+				writingErr = cmd.writeText(c.App.Writer, result)
+			}
+
+			if writingErr != nil {
+				return writingErr
+			}
+
+			if hasErrors {
+				return fmt.Errorf("completed with errors")
+			}
+
+			return nil
+		},
+	}
+
+	return cmd.c
+}
+
+// CollectTests evaluates the script in the collection-only mode and returns the tests tree.
+func (cmd *command) CollectTests(
+	pCtx context.Context,
+	filePath string,
+	maxExecTime time.Duration,
+) ([]js.TestNode, error) {
+	script, readErr := os.ReadFile(filePath)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	ctx, cancel := context.WithTimeout(pCtx, maxExecTime)
+	defer cancel()
+
+	interpreter, createErr := js.NewRuntime(
+		ctx,
+		log.NewNop(),
+		js.WithoutNetwork(),
+		js.WithPrinter(func(io.Writer, ...any) error { return nil }), // the output is not needed
+	)
+	if createErr != nil {
+		return nil, createErr
+	}
+
+	defer interpreter.Close()
+
+	go func() { // events are not needed, but the channel must be read
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case _, ok := <-interpreter.Events():
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	var timedOut atomic.Bool
+
+	t := time.AfterFunc(maxExecTime, func() {
+		timedOut.Store(true)
+		interpreter.Interrupt(fmt.Sprintf("script evaluation time exceeded (%s)", maxExecTime))
+	})
+
+	defer t.Stop()
+
+	tests, err := interpreter.CollectTests(filePath, string(script))
+	if err != nil && timedOut.Load() { // the interrupted by timeout script is an error, not an incomplete list
+		return nil, fmt.Errorf("script evaluation time exceeded (%s)", maxExecTime)
+	}
+
+	return tests, err
+}
+
+func (cmd *command) writeJSON(w io.Writer, result []fileTests) error {
+	j, err := jsoniter.ConfigFastest.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(j))
+
+	return err
+}
+
+var colorDescribe = text.Colors{text.Bold} //nolint:gochecknoglobals
+
+func (cmd *command) writeText(w io.Writer, result []fileTests) error {
+	var lw = list.NewWriter()
+
+	lw.SetStyle(list.StyleConnectedLight)
+
+	var appendNodes func([]js.TestNode)
+
+	appendNodes = func(nodes []js.TestNode) {
+		for _, node := range nodes {
+			if node.Type == "describe" {
+				lw.AppendItem(colorDescribe.Sprint(node.Name))
+				lw.Indent()
+				appendNodes(node.Children) // recursive call
+				lw.UnIndent()
+			} else {
+				lw.AppendItem(node.Name)
+			}
+		}
+	}
+
+	for _, item := range result {
+		if item.Error != "" {
+			lw.AppendItem(item.File + " (" + item.Error + ")")
+
+			continue
+		}
+
+		if item.Warning != "" {
+			lw.AppendItem(item.File + " (" + item.Warning + ")")
+		} else {
+			lw.AppendItem(item.File)
+		}
+
+		lw.Indent()
+		appendNodes(item.Tests)
+		lw.UnIndent()
+	}
+
+	_, err := fmt.Fprintln(w, lw.Render())
+
+	return err
+}
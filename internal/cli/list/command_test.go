@@ -0,0 +1,145 @@
+package list_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/cli/list"
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+
+	var path = filepath.Join(t.TempDir(), "script.js")
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var (
+		out bytes.Buffer
+		app = &cli.App{Writer: &out, Commands: []*cli.Command{list.NewCommand(log.NewNop(), config.Config{})}}
+	)
+
+	err := app.Run(append([]string{"poke", "list"}, args...))
+
+	return out.String(), err
+}
+
+func TestCommand_Text(t *testing.T) {
+	text.DisableColors()
+
+	var path = writeScript(t, `
+test('foo', () => {})
+
+describe('bar', () => {
+  it('baz', () => {})
+})`)
+
+	out, err := runCommand(t, path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "── "+path+"\n   ├─ foo\n   └─ bar\n      └─ baz\n", out)
+
+	path = writeScript(t, `test('foo', () => {}); mustBe.equals(get('http://127.0.0.1:1/').status, 200)`)
+
+	out, err = runCommand(t, path)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "── "+path+" (the tests list may be incomplete")
+	assert.Contains(t, out, "└─ foo\n")
+
+	path = writeScript(t, `test('foo', () => {`)
+
+	out, err = runCommand(t, path)
+	assert.EqualError(t, err, "completed with errors")
+	assert.Contains(t, out, "── "+path+" (SyntaxError")
+	assert.NotContains(t, out, "foo")
+}
+
+func TestCommand_JSON(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveArgs    []string
+		giveScript  string
+		wantTests   int
+		wantError   string
+		wantWarning string
+		wantErr     string
+	}{
+		"success": {
+			giveScript: `test('foo', () => {}); describe('bar', () => { it('baz', () => {}) })`,
+			wantTests:  2,
+		},
+		"incomplete tests list": {
+			giveScript: `
+test('foo', () => {})
+
+mustBe.equals(get('http://127.0.0.1:1/').status, 200)
+
+test('bar', () => {})`,
+			wantTests:   1,
+			wantWarning: "the tests list may be incomplete",
+		},
+		"syntax error": {
+			giveScript: `test('foo', () => {`,
+			wantError:  "SyntaxError",
+			wantErr:    "completed with errors",
+		},
+		"evaluation timeout": {
+			giveArgs:   []string{"--max-script-exec-time", "50ms"},
+			giveScript: `test('foo', () => {}); get('http://127.0.0.1:1/'); while (true) {}`,
+			wantError:  "script evaluation time exceeded (50ms)",
+			wantErr:    "completed with errors",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var path = writeScript(t, tt.giveScript)
+
+			out, err := runCommand(t, append(append([]string{"--format", "json"}, tt.giveArgs...), path)...)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var result []struct {
+				File    string `json:"file"`
+				Tests   []any  `json:"tests"`
+				Error   string `json:"error"`
+				Warning string `json:"warning"`
+			}
+
+			require.NoError(t, jsoniter.ConfigFastest.Unmarshal([]byte(out), &result))
+			require.Len(t, result, 1)
+
+			assert.Equal(t, path, result[0].File)
+			assert.Len(t, result[0].Tests, tt.wantTests)
+			assert.Contains(t, result[0].Error, tt.wantError)
+			assert.Contains(t, result[0].Warning, tt.wantWarning)
+		})
+	}
+}
+
+func TestCommand_Errors(t *testing.T) {
+	var path = writeScript(t, `test('foo', () => {})`)
+
+	_, err := runCommand(t, "--format", "yaml", path)
+	assert.EqualError(t, err, "unsupported output format: yaml")
+
+	_, err = runCommand(t, filepath.Join(t.TempDir(), "*.js"))
+	assert.ErrorContains(t, err, "no files found in")
+}
@@ -0,0 +1,256 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+
+	"github.com/tarampampam/poke/internal/js"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
+	exitCommand        = ".exit"
+	helpCommand        = ".help"
+	scriptName         = "<repl>"
+)
+
+type command struct {
+	c *cli.Command
+}
+
+// NewCommand creates `repl` command.
+func NewCommand(l log.Logger) *cli.Command {
+	var cmd = command{}
+
+	cmd.c = &cli.Command{
+		Name:  "repl",
+		Usage: "Start an interactive shell with the same globals as the scripts have",
+		Description: "Multi-line input is supported (the code is evaluated when it is complete), use the Tab key for " +
+			"the global objects and their methods completion, and the Up/Down keys for the history navigation",
+		Action: func(c *cli.Context) error {
+			var ctx, cancel = context.WithCancel(c.Context)
+			defer cancel()
+
+			interpreter, createErr := js.NewRuntime(ctx, l)
+			if createErr != nil {
+				return createErr
+			}
+
+			defer interpreter.Close()
+
+			go func() { // events are not needed, but the channel must be read
+				for range interpreter.Events() {
+				}
+			}()
+
+			var reader = cmd.newLineReader(interpreter)
+
+			return cmd.loop(ctx, reader, os.Stdout, interpreter)
+		},
+	}
+
+	return cmd.c
+}
+
+// lineReader reads the user input line by line.
+type lineReader interface {
+	ReadLine(continuation bool) (string, error)
+}
+
+// terminalReader reads the lines from the terminal with the line editing, history and auto-completion support.
+type terminalReader struct {
+	fd int
+	t  *term.Terminal
+}
+
+func (r *terminalReader) ReadLine(continuation bool) (string, error) {
+	if continuation {
+		r.t.SetPrompt(continuationPrompt)
+	} else {
+		r.t.SetPrompt(prompt)
+	}
+
+	state, err := term.MakeRaw(r.fd) // raw mode is enabled only for reading, so Ctrl+C interrupts the evaluation
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = term.Restore(r.fd, state) }()
+
+	return r.t.ReadLine()
+}
+
+// plainReader reads the lines from the non-terminal input (e.g. piped).
+type plainReader struct{ s *bufio.Scanner }
+
+func (r *plainReader) ReadLine(bool) (string, error) {
+	if r.s.Scan() {
+		return r.s.Text(), nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return "", err
+	}
+
+	return "", io.EOF
+}
+
+func (cmd *command) newLineReader(interpreter *js.Runtime) lineReader {
+	var fd = int(os.Stdin.Fd())
+
+	if !term.IsTerminal(fd) {
+		return &plainReader{s: bufio.NewScanner(os.Stdin)}
+	}
+
+	var t = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, prompt)
+
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		newLine, newPos, candidates := complete(interpreter, line, pos)
+
+		if len(candidates) > 1 {
+			_, _ = fmt.Fprintln(t, strings.Join(candidates, "  "))
+		}
+
+		return newLine, newPos, true
+	}
+
+	return &terminalReader{fd: fd, t: t}
+}
+
+var (
+	colorResult = text.Colors{text.FgHiBlack} //nolint:gochecknoglobals
+	colorError  = text.Colors{text.FgRed}     //nolint:gochecknoglobals
+)
+
+func (cmd *command) loop(ctx context.Context, reader lineReader, out io.Writer, interpreter *js.Runtime) error {
+	var buf strings.Builder
+
+	for ctx.Err() == nil {
+		line, readErr := reader.ReadLine(buf.Len() > 0)
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) {
+				return nil
+			}
+
+			return readErr
+		}
+
+		if buf.Len() == 0 {
+			switch strings.TrimSpace(line) {
+			case "":
+				continue
+
+			case exitCommand:
+				return nil
+
+			case helpCommand:
+				_, _ = fmt.Fprintf(out, "%s\texit the shell (or press Ctrl+D)\n%s\tprint this help\n", exitCommand, helpCommand)
+
+				continue
+			}
+		}
+
+		buf.WriteString(line)
+		buf.WriteRune('\n')
+
+		result, evalErr := cmd.eval(interpreter, buf.String())
+		if errors.Is(evalErr, js.ErrIncompleteInput) {
+			continue // wait for the rest of the code
+		}
+
+		buf.Reset()
+
+		if evalErr != nil {
+			_, _ = fmt.Fprintln(out, colorError.Sprint(evalErr.Error()))
+		} else {
+			_, _ = fmt.Fprintln(out, colorResult.Sprint(result))
+		}
+	}
+
+	return nil
+}
+
+// eval evaluates the code. The evaluation can be interrupted using the Ctrl+C.
+func (cmd *command) eval(interpreter *js.Runtime, code string) (string, error) {
+	var (
+		sigs = make(chan os.Signal, 1)
+		done = make(chan struct{})
+	)
+
+	signal.Notify(sigs, os.Interrupt)
+
+	defer func() { signal.Stop(sigs); close(done) }()
+
+	go func() {
+		select {
+		case <-done:
+		case <-sigs:
+			interpreter.Interrupt("interrupted by the user")
+		}
+	}()
+
+	return interpreter.Eval(scriptName, code)
+}
+
+// complete returns the line with completed global object (or its property) name under the cursor. If there are
+// several candidates, the longest common prefix is used, and the candidates list is returned.
+func complete(interpreter *js.Runtime, line string, pos int) (newLine string, newPos int, candidates []string) {
+	var start = pos
+
+	for start > 0 && isCompletable(rune(line[start-1])) {
+		start--
+	}
+
+	var (
+		word         = line[start:pos]
+		path, prefix = "", word
+	)
+
+	if i := strings.LastIndexByte(word, '.'); i >= 0 {
+		path, prefix = word[:i], word[i+1:]
+	}
+
+	for _, name := range interpreter.Completions(path) {
+		if strings.HasPrefix(name, prefix) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+
+	var common = candidates[0]
+
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	return line[:pos-len(prefix)] + common + line[pos:], pos - len(prefix) + len(common), candidates
+}
+
+func isCompletable(r rune) bool {
+	return r == '.' || r == '_' || r == '$' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
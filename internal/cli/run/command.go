@@ -2,9 +2,17 @@ package run
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -12,14 +20,18 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/bmatcuk/doublestar/v4"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/urfave/cli/v2"
 
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/files"
+	"github.com/tarampampam/poke/internal/har"
 	"github.com/tarampampam/poke/internal/js"
+	"github.com/tarampampam/poke/internal/js/addons"
 	"github.com/tarampampam/poke/internal/js/events"
 	"github.com/tarampampam/poke/internal/js/printer"
 	"github.com/tarampampam/poke/internal/log"
+	"github.com/tarampampam/poke/internal/version"
 )
 
 type command struct {
@@ -27,11 +39,29 @@ type command struct {
 }
 
 // NewCommand creates `run` command.
-func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
+func NewCommand(l log.Logger, cfg config.Config) *cli.Command { //nolint:funlen
 	const (
 		syncFlagName              = "sync"
 		threadsCountFlagName      = "threads"
 		maxScriptExecTimeFlagName = "max-script-exec-time"
+		evalFlagName              = "eval"
+		fetchTimeoutFlagName      = "fetch-timeout"
+		tlsCAFlagName             = "tls-ca"
+		tlsCertFlagName           = "tls-cert"
+		tlsKeyFlagName            = "tls-key"
+		tlsMinVersionFlagName     = "tls-min-version"
+		tlsServerNameFlagName     = "tls-server-name"
+		insecureFlagName          = "insecure"
+		baseURLFlagName           = "base-url"
+		headerFlagName            = "header"
+		queryFlagName             = "query"
+		proxyFlagName             = "proxy"
+		harFlagName               = "har"
+		harMaxBodySizeFlagName    = "har-max-body-size"
+		recordFlagName            = "record"
+		replayFlagName            = "replay"
+		replayStrictFlagName      = "replay-strict"
+		httpDebugFlagName         = "http-debug"
 	)
 
 	var cmd = command{}
@@ -41,8 +71,13 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 		ArgsUsage:   "<files-or-directories...>",
 		Aliases:     []string{"r"},
 		Usage:       "Run poke files",
-		Description: "Wildcards are supported, e.g. './tests/**/*.js'",
+		Description: "Wildcards are supported, e.g. './tests/**/*.js'. Use '-' to read the script from stdin",
 		Flags: []cli.Flag{
+			&rawStringsFlag{GenericFlag: cli.GenericFlag{
+				Name:    evalFlagName,
+				Aliases: []string{"e"},
+				Usage:   "run the inline code, e.g. -e 'assert.true(get(\"https://example.com\").ok)'",
+			}},
 			&cli.BoolFlag{
 				Name:    syncFlagName,
 				Aliases: []string{"s"},
@@ -59,32 +94,187 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 				Usage: "maximum execution time of each script, e.g. '10s' or '1m'",
 				Value: 60 * time.Second, //nolint:gomnd // default value
 			},
+			&cli.DurationFlag{
+				Name:  fetchTimeoutFlagName,
+				Usage: "default timeout for the HTTP requests, e.g. '5s' (zero means no timeout)",
+				Value: 60 * time.Second, //nolint:gomnd // default value
+			},
+			&cli.StringSliceFlag{
+				Name:  tlsCAFlagName,
+				Usage: "path to the additional trusted CA certificate (PEM) for the HTTP requests",
+			},
+			&cli.StringFlag{
+				Name:  tlsCertFlagName,
+				Usage: "path to the client certificate (PEM) for the HTTP requests (mTLS)",
+			},
+			&cli.StringFlag{
+				Name:  tlsKeyFlagName,
+				Usage: "path to the client certificate key (PEM) for the HTTP requests (mTLS)",
+			},
+			&cli.StringFlag{
+				Name:  tlsMinVersionFlagName,
+				Usage: "minimum TLS version for the HTTP requests (1.0, 1.1, 1.2 or 1.3)",
+			},
+			&cli.StringFlag{
+				Name:  tlsServerNameFlagName,
+				Usage: "server name (SNI) for the HTTP requests",
+			},
+			&cli.BoolFlag{
+				Name:    insecureFlagName,
+				Aliases: []string{"k"},
+				Usage:   "skip the server certificate verification for the HTTP requests",
+			},
+			&cli.StringFlag{
+				Name:  baseURLFlagName,
+				Usage: "base URL for the HTTP requests with relative URLs, e.g. 'https://staging.example.com/api'",
+			},
+			&rawStringsFlag{GenericFlag: cli.GenericFlag{
+				Name:    headerFlagName,
+				Aliases: []string{"H"},
+				Usage:   "default header for the HTTP requests in the 'Name: value' format",
+			}},
+			&rawStringsFlag{GenericFlag: cli.GenericFlag{
+				Name:  queryFlagName,
+				Usage: "default query parameter for the HTTP requests in the 'name=value' format",
+			}},
+			&cli.StringFlag{
+				Name:  proxyFlagName,
+				Usage: "proxy URL for the HTTP requests (HTTP_PROXY, HTTPS_PROXY and NO_PROXY env variables are used by default)",
+			},
+			&cli.StringFlag{
+				Name:  harFlagName,
+				Usage: "directory to write the HTTP traffic of each script into (HAR 1.2 files, with the redacted secrets)",
+			},
+			&cli.UintFlag{
+				Name:  harMaxBodySizeFlagName,
+				Usage: "maximum size (in bytes) of the request/response body stored in the HAR files",
+				Value: 1 << 20, //nolint:gomnd // default value (1 MiB)
+			},
+			&cli.StringFlag{
+				Name:  recordFlagName,
+				Usage: "directory to record the HTTP interactions (cassettes) into (a subdirectory per script, overwritten)",
+			},
+			&cli.StringFlag{
+				Name:  replayFlagName,
+				Usage: "directory to replay the recorded HTTP interactions (cassettes) from",
+			},
+			&cli.BoolFlag{
+				Name:  replayStrictFlagName,
+				Usage: "fail the HTTP requests that have no recorded interactions (instead of sending them)",
+			},
+			&cli.BoolFlag{
+				Name:  httpDebugFlagName,
+				Usage: "log the HTTP requests and responses (use with --verbose, sensitive headers are redacted)",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			if err := cfg.Apply(c); err != nil {
+				return err
+			}
+
 			var (
+				patterns          = c.Args().Slice()
+				inline            = c.Generic(evalFlagName).(*rawStrings).Values()
 				threadsCount      = c.Uint(threadsCountFlagName)
 				maxScriptExecTime = c.Duration(maxScriptExecTimeFlagName)
+				fetchTimeout      = c.Duration(fetchTimeoutFlagName)
+				harDir            = c.String(harFlagName)
+				harMaxBodySize    = int(c.Uint(harMaxBodySizeFlagName))
 			)
 
+			if harDir != "" {
+				if err := os.MkdirAll(harDir, 0o755); err != nil { //nolint:gomnd
+					return fmt.Errorf("cannot create the HAR directory: %w", err)
+				}
+			}
+
+			var fetchOptions = []addons.FetchOption{
+				addons.WithFetchTimeout(fetchTimeout),
+				addons.WithFetchDebug(c.Bool(httpDebugFlagName)),
+			}
+
+			if tlsOptions := (addons.TLSOptions{
+				CA:                 c.StringSlice(tlsCAFlagName),
+				Cert:               c.String(tlsCertFlagName),
+				Key:                c.String(tlsKeyFlagName),
+				MinVersion:         c.String(tlsMinVersionFlagName),
+				ServerName:         c.String(tlsServerNameFlagName),
+				InsecureSkipVerify: c.Bool(insecureFlagName),
+			}); !tlsOptions.IsEmpty() {
+				var tlsConfig = &tls.Config{} //nolint:gosec // the minimal version is set by the user
+
+				if err := tlsOptions.Apply(tlsConfig); err != nil {
+					return fmt.Errorf("wrong TLS options: %w", err)
+				}
+
+				fetchOptions = append(fetchOptions, addons.WithFetchTLSConfig(tlsConfig))
+			}
+
+			if proxy := c.String(proxyFlagName); proxy != "" {
+				proxyURL, err := url.Parse(proxy)
+				if err != nil || proxyURL.Host == "" {
+					return fmt.Errorf("wrong proxy URL %s", proxy)
+				}
+
+				fetchOptions = append(fetchOptions, addons.WithFetchProxy(proxyURL))
+			}
+
+			defaults, defaultsErr := cmd.fetchDefaults(
+				c.String(baseURLFlagName),
+				c.Generic(headerFlagName).(*rawStrings).Values(),
+				c.Generic(queryFlagName).(*rawStrings).Values(),
+			)
+			if defaultsErr != nil {
+				return defaultsErr
+			}
+
+			fetchOptions = append(fetchOptions, addons.WithFetchDefaults(defaults))
+
+			var (
+				cassettesDir  string
+				cassettesMode addons.CassetteMode
+			)
+
+			switch record, replay := c.String(recordFlagName), c.String(replayFlagName); {
+			case record != "" && replay != "":
+				return fmt.Errorf("--%s and --%s flags cannot be used together", recordFlagName, replayFlagName)
+
+			case record != "":
+				cassettesDir, cassettesMode = record, addons.CassetteRecord
+
+			case replay != "":
+				if _, err := os.Stat(replay); err != nil {
+					return fmt.Errorf("wrong cassettes directory: %w", err)
+				}
+
+				if cassettesDir, cassettesMode = replay, addons.CassetteReplay; c.Bool(replayStrictFlagName) {
+					cassettesMode = addons.CassetteReplayStrict
+				}
+			}
+
 			if c.Bool(syncFlagName) || threadsCount == 0 {
 				threadsCount = 1
 			}
 
+			if len(patterns) == 0 && len(inline) == 0 {
+				patterns = cfg.Files(c.Command.Name)
+			}
+
 			var ctx, cancel = context.WithCancel(c.Context) // main context creation
 			defer cancel()
 
 			cmd.subscribeForSystemSignals(ctx, func(_ os.Signal) { cancel() })
 
-			files, findingErr := cmd.FindFiles(c.Args().Slice())
+			found, findingErr := cmd.FindScripts(c.App.Reader, patterns, inline)
 			if findingErr != nil {
 				return findingErr
 			}
 
-			if len(files) == 0 {
-				return fmt.Errorf("no files found in %s", strings.Join(c.Args().Slice(), ", "))
+			if len(found) == 0 {
+				return fmt.Errorf("no files found in %s", strings.Join(patterns, ", "))
 			}
 
-			l.Debug("Found files", log.With("files", files))
+			l.Debug("Found scripts", log.With("scripts", found))
 
 			var (
 				wg           sync.WaitGroup
@@ -95,7 +285,7 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 			)
 
 		runLoop:
-			for _, filePath := range files {
+			for _, src := range found {
 				select {
 				case guard <- struct{}{}: // would block if guard channel is already filled
 					wg.Add(1)
@@ -104,17 +294,57 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 					break runLoop
 				}
 
-				go func(filePath string) {
+				go func(src script) {
 					defer func() { <-guard; /* release the guard */ wg.Done() }()
 
+					var filePath = src.name
+
 					startedAt := time.Now()
 
 					l.Info("Running script", log.With("file", filePath))
 
-					ev, runningErr := cmd.RunScript(ctx, l, filePath, maxScriptExecTime)
+					var options = []js.RuntimeOption{js.WithFetchOptions(fetchOptions...)}
+
+					if cassettesDir != "" { // each script has its own cassettes, so the same requests don't clash
+						var scriptCassettesDir = filepath.Join(cassettesDir, scriptFileName(filePath))
+
+						if cassettesMode == addons.CassetteRecord { // the previous recording must not be mixed in
+							if err := os.RemoveAll(scriptCassettesDir); err != nil {
+								stats.SetError(filePath, err)
+								l.Error("Cannot clear the cassettes directory",
+									log.With("dir", scriptCassettesDir), log.With("error", err),
+								)
+
+								return
+							}
+						}
+
+						options = append(options, js.WithFetchOptions(addons.WithFetchCassette(scriptCassettesDir, cassettesMode)))
+					}
+
+					var recorder *har.Recorder
+
+					// the HAR middleware is added after the cassette (the last middleware is the outer one), so the
+					// replayed traffic is recorded too
+					if harDir != "" {
+						recorder = har.NewRecorder(har.Creator{Name: "poke", Version: version.Version()}, harMaxBodySize)
+						options = append(options, js.WithFetchOptions(addons.WithFetchMiddleware(recorder.Wrap)))
+					}
+
+					ev, runningErr := cmd.RunScript(ctx, l, src, maxScriptExecTime, options...)
 
 					stats.SetDuration(filePath, time.Since(startedAt))
 
+					if recorder != nil { // the traffic is written even if the script failed
+						var harPath = filepath.Join(harDir, harFileName(filePath))
+
+						if err := recorder.WriteFile(harPath); err != nil {
+							l.Error("Cannot write the HAR file", log.With("file", harPath), log.With("error", err))
+						} else {
+							l.Debug("HAR file written", log.With("file", harPath), log.With("entries", recorder.Len()))
+						}
+					}
+
 					if runningErr != nil {
 						stats.SetError(filePath, runningErr)
 						l.Error("Script execution failed", log.With("file", filePath), log.With("error", runningErr))
@@ -134,7 +364,7 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 					} else {
 						l.Success("Script executed successfully", log.With("file", filePath))
 					}
-				}(filePath)
+				}(src)
 			}
 
 			wg.Wait()
@@ -142,7 +372,7 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 
 			stats.SetSummaryDuration(time.Since(groupStartAt))
 
-			if _, err := fmt.Fprintf(os.Stdout, "\n%s\n", stats.ToConsole()); err != nil {
+			if _, err := fmt.Fprintf(c.App.Writer, "\n%s\n", stats.ToConsole()); err != nil {
 				return err
 			}
 
@@ -157,6 +387,55 @@ func NewCommand(l log.Logger) *cli.Command { //nolint:funlen
 	return cmd.c
 }
 
+// fetchDefaults parses the default options for the HTTP requests.
+func (cmd *command) fetchDefaults(baseURL string, headers, query []string) (addons.FetchDefaults, error) {
+	var defaults = addons.FetchDefaults{BaseURL: baseURL, Headers: make(http.Header), Query: make(url.Values)}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return defaults, fmt.Errorf("wrong header %q (expected format is 'Name: value')", header)
+		}
+
+		defaults.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	for _, param := range query {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok || name == "" {
+			return defaults, fmt.Errorf("wrong query parameter %q (expected format is 'name=value')", param)
+		}
+
+		defaults.Query.Add(name, value)
+	}
+
+	return defaults, nil
+}
+
+// harFileName returns the HAR file name for the script (e.g. `tests/foo.js` becomes `tests_foo.js.har`).
+func harFileName(scriptName string) string { return scriptFileName(scriptName) + ".har" }
+
+// scriptFileName returns the file name for the script related files (path separators and special characters are
+// replaced, so `tests/foo.js` becomes `tests_foo.js` and `<inline:1>` becomes `inline_1`).
+func scriptFileName(scriptName string) string {
+	var name = strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		case r == '<' || r == '>':
+			return -1
+		}
+
+		return '_'
+	}, filepath.ToSlash(filepath.Clean(scriptName))), "._")
+
+	if name == "" {
+		name = "script"
+	}
+
+	return name
+}
+
 func (cmd *command) subscribeForSystemSignals(ctx context.Context, fn func(os.Signal)) {
 	var sigs = make(chan os.Signal, 1)
 
@@ -181,19 +460,64 @@ func (cmd *command) subscribeForSystemSignals(ctx context.Context, fn func(os.Si
 	}()
 }
 
-func (cmd *command) FindFiles(in []string) ([]string, error) {
-	var files []string
+// stdinArg is a special argument that means "read the script from stdin".
+const stdinArg = "-"
+
+// script is a source of the JS code to run.
+type script struct {
+	name string                 // file path or a synthetic name (like `<stdin>`) for logs and reports
+	load func() ([]byte, error) // loads the script content
+}
+
+func (s script) String() string { return s.name }
+
+// FindScripts returns the scripts to run - files (found using the patterns), stdin (if the pattern is `-`) and
+// inline code.
+func (cmd *command) FindScripts(stdin io.Reader, patterns, inline []string) ([]script, error) {
+	var (
+		result   = make([]script, 0, len(patterns)+len(inline))
+		hasStdin bool
+	)
+
+	for _, pattern := range patterns {
+		if pattern == stdinArg {
+			if hasStdin {
+				return nil, errors.New("stdin can be used only once")
+			}
+
+			hasStdin = true
+
+			result = append(result, script{name: "<stdin>", load: func() ([]byte, error) { return io.ReadAll(stdin) }})
+
+			continue
+		}
 
-	for _, arg := range in {
-		matches, globErr := doublestar.FilepathGlob(arg)
-		if globErr != nil {
-			return nil, globErr
+		found, err := files.Find(pattern)
+		if err != nil {
+			return nil, err
 		}
 
-		files = append(files, matches...)
+		for _, filePath := range found {
+			var path = filePath
+
+			result = append(result, script{name: path, load: func() ([]byte, error) { return os.ReadFile(path) }})
+		}
 	}
 
-	return files, nil
+	for i, code := range inline {
+		var (
+			content = []byte(code)
+			name    = "<inline>"
+		)
+
+		if len(inline) > 1 {
+			name = fmt.Sprintf("<inline:%d>", i+1)
+		}
+
+		result = append(result, script{name: name, load: func() ([]byte, error) { return content, nil }})
+	}
+
+	return result, nil
 }
 
 var colorLogPrefix = text.Colors{text.FgWhite} //nolint:gochecknoglobals
@@ -201,10 +525,13 @@ var colorLogPrefix = text.Colors{text.FgWhite} //nolint:gochecknoglobals
 func (cmd *command) RunScript( //nolint:funlen
 	pCtx context.Context,
 	log log.Logger,
-	filePath string,
+	src script,
 	maxExecTime time.Duration,
+	options ...js.RuntimeOption,
 ) (events.Events, error) {
-	script, readErr := os.ReadFile(filePath)
+	var filePath = src.name
+
+	content, readErr := src.load()
 	if readErr != nil {
 		return nil, readErr
 	}
@@ -212,11 +539,9 @@ func (cmd *command) RunScript( //nolint:funlen
 	ctx, cancel := context.WithTimeout(pCtx, maxExecTime)
 	defer cancel()
 
-	interpreter, createErr := js.NewRuntime(
-		ctx,
-		log,
+	interpreter, createErr := js.NewRuntime(ctx, log, append([]js.RuntimeOption{
 		js.WithPrinter(printer.StringPrefixPrinter(colorLogPrefix.Sprintf("%s: ", filePath))),
-	)
+	}, options...)...)
 	if createErr != nil {
 		return nil, createErr
 	}
@@ -252,7 +577,7 @@ func (cmd *command) RunScript( //nolint:funlen
 
 	defer t.Stop()
 
-	runErr := interpreter.RunScript(filePath, string(script))
+	runErr := interpreter.RunScript(filePath, string(content))
 	interpreter.Close()
 
 	<-locker
@@ -263,3 +588,42 @@ func (cmd *command) RunScript( //nolint:funlen
 
 	return buf, nil
 }
+
+// rawStringsFlag is the repeatable string flag. Unlike the cli.StringSliceFlag, the values are not split by commas,
+// since the inline code, headers and query parameters may contain them.
+type rawStringsFlag struct{ cli.GenericFlag }
+
+// Apply creates the flag value (a new one for each flag set, so the values are not shared between the command runs).
+func (f *rawStringsFlag) Apply(set *flag.FlagSet) error {
+	f.Value = new(rawStrings)
+
+	return f.GenericFlag.Apply(set)
+}
+
+// rawStrings is the rawStringsFlag value.
+type rawStrings []string
+
+// rawStringsPrefix is the prefix of the serialized value (the value is copied between the flag aliases this way).
+const rawStringsPrefix = "raw-strings:"
+
+func (s *rawStrings) Set(value string) error {
+	if strings.HasPrefix(value, rawStringsPrefix) { // deserializing overwrites the values
+		return json.Unmarshal([]byte(strings.TrimPrefix(value, rawStringsPrefix)), s)
+	}
+
+	*s = append(*s, value)
+
+	return nil
+}
+
+func (s *rawStrings) String() string { return strings.Join(*s, ", ") }
+
+// Serialize implements the cli.Serializer interface.
+func (s *rawStrings) Serialize() string {
+	var data, _ = json.Marshal([]string(*s))
+
+	return rawStringsPrefix + string(data)
+}
+
+// Values returns the flag values.
+func (s *rawStrings) Values() []string { return *s }
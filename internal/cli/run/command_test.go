@@ -0,0 +1,171 @@
+package run
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/config"
+	"github.com/tarampampam/poke/internal/log"
+)
+
+func TestScriptFileName(t *testing.T) {
+	for give, want := range map[string]string{
+		"foo.js":              "foo.js",
+		"tests/foo.js":        "tests_foo.js",
+		"./tests/../bar.js":   "bar.js",
+		"/abs/path/baz.js":    "abs_path_baz.js",
+		"tests/with space.js": "tests_with_space.js",
+		"<inline>":            "inline",
+		"<inline:2>":          "inline_2",
+		"<stdin>":             "stdin",
+		"<>":                  "script",
+	} {
+		assert.Equal(t, want, scriptFileName(give), give)
+	}
+}
+
+func TestCommand_FindScripts(t *testing.T) {
+	var dir = t.TempDir()
+
+	for _, name := range []string{"a.js", "b.js"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("// "+name), 0o600))
+	}
+
+	for name, tt := range map[string]struct {
+		givePatterns []string
+		giveInline   []string
+		wantNames    []string
+		wantContents []string
+		wantError    string
+	}{
+		"files": {
+			givePatterns: []string{filepath.Join(dir, "*.js")},
+			wantNames:    []string{filepath.Join(dir, "a.js"), filepath.Join(dir, "b.js")},
+			wantContents: []string{"// a.js", "// b.js"},
+		},
+		"stdin": {
+			givePatterns: []string{"-"},
+			wantNames:    []string{"<stdin>"},
+			wantContents: []string{"// stdin"},
+		},
+		"stdin used twice": {
+			givePatterns: []string{"-", "-"},
+			wantError:    "stdin can be used only once",
+		},
+		"single inline code": {
+			giveInline:   []string{"foo(1, 2)"},
+			wantNames:    []string{"<inline>"},
+			wantContents: []string{"foo(1, 2)"},
+		},
+		"files, stdin and inline code": {
+			givePatterns: []string{filepath.Join(dir, "a.js"), "-"},
+			giveInline:   []string{"foo()", "bar()"},
+			wantNames:    []string{filepath.Join(dir, "a.js"), "<stdin>", "<inline:1>", "<inline:2>"},
+			wantContents: []string{"// a.js", "// stdin", "foo()", "bar()"},
+		},
+		"wrong pattern": {
+			givePatterns: []string{"[.js"},
+			wantError:    "syntax error in pattern",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			found, err := (&command{}).FindScripts(strings.NewReader("// stdin"), tt.givePatterns, tt.giveInline)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, found, len(tt.wantNames))
+
+			for i, s := range found {
+				assert.Equal(t, tt.wantNames[i], s.name)
+
+				content, loadErr := s.load()
+				require.NoError(t, loadErr)
+				assert.Equal(t, tt.wantContents[i], string(content))
+			}
+		})
+	}
+}
+
+func TestCommand_Run(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveArgs   []string
+		giveStdin  string
+		wantOutput string
+		wantError  string
+	}{
+		"inline code with commas is not split": {
+			giveArgs:   []string{"-e", "assert.equals([1, 2].join(), '1,2')", "-e", "assert.true(true)"},
+			wantOutput: "TOTAL FILES: 2",
+		},
+		"failed assertion in the inline code": {
+			giveArgs:  []string{"-e", "assert.equals(1, 2)"},
+			wantError: "completed with errors",
+		},
+		"stdin": {
+			giveArgs:   []string{"-"},
+			giveStdin:  "assert.equals([1, 2].join(), '1,2')",
+			wantOutput: "<stdin>",
+		},
+		"failed assertion in stdin": {
+			giveArgs:  []string{"-"},
+			giveStdin: "assert.true(false)",
+			wantError: "completed with errors",
+		},
+		"nothing to run": {
+			giveArgs:  []string{filepath.Join(t.TempDir(), "*.js")},
+			wantError: "no files found",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var (
+				out bytes.Buffer
+				app = &cli.App{
+					Reader:   strings.NewReader(tt.giveStdin),
+					Writer:   &out,
+					Commands: []*cli.Command{NewCommand(log.NewNop(), config.Config{})},
+				}
+			)
+
+			err := app.Run(append([]string{"poke", "run"}, tt.giveArgs...))
+
+			assert.Contains(t, out.String(), tt.wantOutput)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRawStrings(t *testing.T) {
+	var s rawStrings
+
+	require.NoError(t, s.Set("foo, bar"))
+	require.NoError(t, s.Set("baz"))
+	assert.Equal(t, []string{"foo, bar", "baz"}, s.Values())
+
+	var copied rawStrings
+
+	require.NoError(t, copied.Set(s.Serialize())) // the values are copied between the flag aliases this way
+	assert.Equal(t, s.Values(), copied.Values())
+
+	assert.Equal(t, "foo, bar, baz", s.String())
+}
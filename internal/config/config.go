@@ -0,0 +1,149 @@
+// Package config contains the configuration file (poke.yaml) loading and applying.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the default configuration file name.
+const DefaultFileName = "poke.yaml"
+
+// DefaultTestsDir is the default tests directory (created by the `init` command with the configuration file inside).
+const DefaultTestsDir = "tests"
+
+// Locations returns the paths, where the configuration file is searched (in order) when the path is not set
+// explicitly - the current directory and the default tests directory.
+func Locations() []string {
+	return []string{DefaultFileName, filepath.Join(DefaultTestsDir, DefaultFileName)}
+}
+
+// filesKey is a (non-flag) key with the list of files for the command.
+const filesKey = "files"
+
+// Config is a configuration file content. Top-level keys are the command names, and nested keys are the command
+// flag names (e.g. `run: {threads: 4, max-script-exec-time: 30s}`). Additionally, the list of files (patterns) can
+// be set using the `files` key.
+type Config map[string]map[string]any
+
+// Parse parses the configuration file content.
+func Parse(content []byte) (Config, error) {
+	var cfg = make(Config)
+
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("wrong configuration file format: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// FromFile reads and parses the configuration file. The relative files (patterns) are resolved against the
+// configuration file directory.
+func FromFile(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, options := range cfg {
+		var list, _ = options[filesKey].([]any)
+
+		for i, v := range list {
+			if pattern := fmt.Sprint(v); pattern != "-" && !filepath.IsAbs(pattern) { // "-" means stdin
+				list[i] = filepath.Join(filepath.Dir(path), pattern)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Discover loads the configuration file from the path, or (if the path is empty) from the first existing default
+// location (see Locations). The path of the loaded file is returned (it is empty if no file was found).
+func Discover(path string) (Config, string, error) {
+	if path != "" {
+		cfg, err := FromFile(path)
+
+		return cfg, path, err
+	}
+
+	for _, location := range Locations() {
+		if cfg, err := FromFile(location); err == nil {
+			return cfg, location, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", err
+		}
+	}
+
+	return make(Config), "", nil
+}
+
+// Files returns the list of files (patterns) for the command.
+func (cfg Config) Files(command string) []string {
+	var list, _ = cfg[command][filesKey].([]any)
+
+	var result = make([]string, 0, len(list))
+
+	for _, v := range list {
+		result = append(result, fmt.Sprint(v))
+	}
+
+	return result
+}
+
+// Apply sets the flag values of the current command from the configuration. Flags that were set explicitly (using
+// the command line arguments or environment variables) are not overridden.
+func (cfg Config) Apply(c *cli.Context) error {
+	if c.Command == nil {
+		return nil
+	}
+
+	for name, value := range cfg[c.Command.Name] {
+		if name == filesKey {
+			continue
+		}
+
+		if !hasFlag(c.Command, name) {
+			return fmt.Errorf("unknown option %s for the %s command", name, c.Command.Name)
+		}
+
+		if c.IsSet(name) {
+			continue
+		}
+
+		var values, isList = value.([]any)
+
+		if !isList {
+			values = []any{value}
+		}
+
+		for _, v := range values {
+			if err := c.Set(name, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("wrong %s option value for the %s command: %w", name, c.Command.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasFlag checks whether the command has a flag with the given name (aliases are ignored).
+func hasFlag(cmd *cli.Command, name string) bool {
+	for _, flag := range cmd.Flags {
+		if flag.Names()[0] == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,234 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tarampampam/poke/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := config.Parse([]byte(`
+run:
+  files: [./foo/*.js, ./bar.js]
+  threads: 4
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"./foo/*.js", "./bar.js"}, cfg.Files("run"))
+	assert.Equal(t, 4, cfg["run"]["threads"])
+	assert.Empty(t, cfg.Files("list"))
+
+	_, err = config.Parse([]byte(`foo: [bar`))
+	assert.Error(t, err)
+}
+
+func TestFromFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), config.DefaultFileName)
+
+	require.NoError(t, os.WriteFile(path, []byte("run: {sync: true}"), 0o600))
+
+	cfg, err := config.FromFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, true, cfg["run"]["sync"])
+
+	_, err = config.FromFile(filepath.Join(t.TempDir(), "foo.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFromFile_RelativeFiles(t *testing.T) {
+	var dir = t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.DefaultFileName), []byte(`
+run: {files: ['**/*.js', '/abs/*.js', '-']}
+list: {files: ['../foo.js']}
+`), 0o600))
+
+	cfg, err := config.FromFile(filepath.Join(dir, config.DefaultFileName))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "**", "*.js"), "/abs/*.js", "-"}, cfg.Files("run"))
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(dir), "foo.js")}, cfg.Files("list"))
+}
+
+// chdir changes the working directory for the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestDiscover(t *testing.T) {
+	var write = func(t *testing.T, path, content string) {
+		t.Helper()
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	for name, tt := range map[string]struct {
+		giveFiles map[string]string
+		givePath  string
+		wantPath  string
+		wantFiles []string
+		wantError error
+	}{
+		"no configuration files": {},
+		"current directory": {
+			giveFiles: map[string]string{"poke.yaml": "run: {files: [a.js]}"},
+			wantPath:  "poke.yaml",
+			wantFiles: []string{"a.js"},
+		},
+		"tests directory": {
+			giveFiles: map[string]string{"tests/poke.yaml": "run: {files: ['**/*.js']}"},
+			wantPath:  filepath.Join("tests", "poke.yaml"),
+			wantFiles: []string{filepath.Join("tests", "**", "*.js")},
+		},
+		"current directory takes precedence": {
+			giveFiles: map[string]string{"poke.yaml": "run: {files: [a.js]}", "tests/poke.yaml": "run: {files: [b.js]}"},
+			wantPath:  "poke.yaml",
+			wantFiles: []string{"a.js"},
+		},
+		"explicit path takes precedence": {
+			giveFiles: map[string]string{"poke.yaml": "run: {files: [a.js]}", "custom/foo.yaml": "run: {files: [c.js]}"},
+			givePath:  filepath.Join("custom", "foo.yaml"),
+			wantPath:  filepath.Join("custom", "foo.yaml"),
+			wantFiles: []string{filepath.Join("custom", "c.js")},
+		},
+		"missing explicit path": {
+			giveFiles: map[string]string{"poke.yaml": "run: {files: [a.js]}"},
+			givePath:  "foo.yaml",
+			wantError: os.ErrNotExist,
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+
+			for path, content := range tt.giveFiles {
+				write(t, filepath.FromSlash(path), content)
+			}
+
+			cfg, path, err := config.Discover(tt.givePath)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.ElementsMatch(t, tt.wantFiles, cfg.Files("run"))
+		})
+	}
+
+	t.Run("wrong file in the default location", func(t *testing.T) {
+		chdir(t, t.TempDir())
+		write(t, filepath.Join("tests", "poke.yaml"), "foo: [bar")
+
+		_, _, err := config.Discover("")
+		assert.ErrorContains(t, err, "wrong configuration file format")
+	})
+}
+
+func newContext(t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+
+	var (
+		cmd = &cli.Command{
+			Name: "run",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "sync", Aliases: []string{"s"}},
+				&cli.UintFlag{Name: "threads", EnvVars: []string{"TEST_POKE_THREADS"}},
+				&cli.DurationFlag{Name: "timeout"},
+				&cli.StringSliceFlag{Name: "header"},
+			},
+		}
+		set = flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	)
+
+	for _, f := range cmd.Flags {
+		require.NoError(t, f.Apply(set))
+	}
+
+	require.NoError(t, set.Parse(args))
+
+	var c = cli.NewContext(cli.NewApp(), set, nil)
+
+	c.Command = cmd
+
+	return c
+}
+
+func TestConfig_Apply(t *testing.T) {
+	var c = newContext(t, "--threads", "2")
+
+	assert.NoError(t, config.Config{"run": {
+		"files":   []any{"foo.js"},
+		"sync":    true,
+		"threads": 8,
+		"timeout": "10s",
+		"header":  []any{"foo", "bar"},
+	}}.Apply(c))
+
+	assert.True(t, c.Bool("sync"))
+	assert.Equal(t, uint(2), c.Uint("threads")) // explicitly set flag is not overridden
+	assert.Equal(t, 10*time.Second, c.Duration("timeout"))
+	assert.Equal(t, []string{"foo", "bar"}, c.StringSlice("header"))
+}
+
+func TestConfig_ApplyErrors(t *testing.T) {
+	assert.ErrorContains(t,
+		config.Config{"run": {"foo": "bar"}}.Apply(newContext(t)),
+		"unknown option foo for the run command",
+	)
+
+	assert.ErrorContains(t,
+		config.Config{"run": {"threads": "many"}}.Apply(newContext(t)),
+		"wrong threads option value for the run command",
+	)
+
+	assert.NoError(t, config.Config{"list": {"foo": "bar"}}.Apply(newContext(t))) // another command
+}
+
+func TestConfig_ApplyPrecedence(t *testing.T) {
+	var cfg = config.Config{"run": {"threads": 8}}
+
+	for name, tt := range map[string]struct {
+		giveEnv  string
+		giveArgs []string
+		want     uint
+	}{
+		"file only":            {want: 8},
+		"env over file":        {giveEnv: "4", want: 4},
+		"flag over env":        {giveEnv: "4", giveArgs: []string{"--threads", "2"}, want: 2},
+		"flag over file":       {giveArgs: []string{"--threads", "2"}, want: 2},
+		"empty env is ignored": {giveEnv: "", want: 8},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			if tt.giveEnv != "" {
+				t.Setenv("TEST_POKE_THREADS", tt.giveEnv)
+			}
+
+			var c = newContext(t, tt.giveArgs...)
+
+			require.NoError(t, cfg.Apply(c))
+			assert.Equal(t, tt.want, c.Uint("threads"))
+		})
+	}
+}
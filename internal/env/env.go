@@ -10,7 +10,8 @@ const (
 	NoColors    envVariable = "NO_COLOR" // docs: <https://no-color.org/>
 	Term        envVariable = "TERM"
 
-	LogLevel envVariable = "LOG_LEVEL" // logging level
+	LogLevel   envVariable = "LOG_LEVEL"   // logging level
+	ConfigFile envVariable = "POKE_CONFIG" // path to the configuration file
 )
 
 // String returns environment variable name in the string representation.
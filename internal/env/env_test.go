@@ -12,6 +12,8 @@ func TestConstants(t *testing.T) {
 	require.Equal(t, "FORCE_COLOR", string(ForceColors))
 	require.Equal(t, "NO_COLOR", string(NoColors))
 	require.Equal(t, "TERM", string(Term))
+	require.Equal(t, "LOG_LEVEL", string(LogLevel))
+	require.Equal(t, "POKE_CONFIG", string(ConfigFile))
 }
 
 func TestEnvVariable_Lookup(t *testing.T) {
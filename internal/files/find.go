@@ -0,0 +1,20 @@
+// Package files contains helpers for working with the script files.
+package files
+
+import "github.com/bmatcuk/doublestar/v4"
+
+// Find returns all the files that match the given patterns (wildcards like `./tests/**/*.js` are supported).
+func Find(patterns ...string) ([]string, error) {
+	var found []string
+
+	for _, pattern := range patterns {
+		matches, globErr := doublestar.FilepathGlob(pattern)
+		if globErr != nil {
+			return nil, globErr
+		}
+
+		found = append(found, matches...)
+	}
+
+	return found, nil
+}
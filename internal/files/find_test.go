@@ -0,0 +1,37 @@
+package files_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/files"
+)
+
+func TestFind(t *testing.T) {
+	var dir = t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo", "bar"), 0o755))
+
+	for _, name := range []string{"a.js", "foo/b.js", "foo/bar/c.js", "foo/bar/d.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	}
+
+	found, err := files.Find(filepath.Join(dir, "**", "*.js"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "a.js"),
+		filepath.Join(dir, "foo", "b.js"),
+		filepath.Join(dir, "foo", "bar", "c.js"),
+	}, found)
+
+	found, err = files.Find(filepath.Join(dir, "a.js"), filepath.Join(dir, "foo", "*.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.js"), filepath.Join(dir, "foo", "b.js")}, found)
+
+	_, err = files.Find("[")
+	assert.Error(t, err)
+}
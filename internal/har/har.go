@@ -0,0 +1,420 @@
+// Package har records the HTTP traffic into the HAR 1.2 format (http://www.softwareishard.com/blog/har-12-spec/),
+// so it can be opened in the browser devtools. Sensitive headers and cookie values are redacted.
+package har
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tarampampam/poke/internal/redact"
+)
+
+type (
+	// Log is the root HAR object.
+	Log struct {
+		Version string  `json:"version"`
+		Creator Creator `json:"creator"`
+		Entries []Entry `json:"entries"`
+	}
+
+	// Creator is the application that created the log.
+	Creator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	// Entry is a single HTTP request with its response.
+	Entry struct {
+		StartedDateTime string   `json:"startedDateTime"`
+		Time            float64  `json:"time"` // total time in milliseconds
+		Request         Request  `json:"request"`
+		Response        Response `json:"response"`
+		Cache           struct{} `json:"cache"`
+		Timings         Timings  `json:"timings"`
+		Comment         string   `json:"comment,omitempty"` // the error message for the failed requests
+	}
+
+	// Request is the performed request details.
+	Request struct {
+		Method      string    `json:"method"`
+		URL         string    `json:"url"`
+		HTTPVersion string    `json:"httpVersion"`
+		Cookies     []Pair    `json:"cookies"`
+		Headers     []Pair    `json:"headers"`
+		QueryString []Pair    `json:"queryString"`
+		PostData    *PostData `json:"postData,omitempty"`
+		HeadersSize int       `json:"headersSize"`
+		BodySize    int       `json:"bodySize"`
+	}
+
+	// Response is the received response details.
+	Response struct {
+		Status      int     `json:"status"`
+		StatusText  string  `json:"statusText"`
+		HTTPVersion string  `json:"httpVersion"`
+		Cookies     []Pair  `json:"cookies"`
+		Headers     []Pair  `json:"headers"`
+		Content     Content `json:"content"`
+		RedirectURL string  `json:"redirectURL"`
+		HeadersSize int     `json:"headersSize"`
+		BodySize    int     `json:"bodySize"`
+	}
+
+	// Pair is a name-value pair (header, cookie or query parameter).
+	Pair struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// PostData is the request body.
+	PostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Comment  string `json:"comment,omitempty"`
+	}
+
+	// Content is the response body.
+	Content struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+		Encoding string `json:"encoding,omitempty"` // "base64" for the binary content
+		Comment  string `json:"comment,omitempty"`
+	}
+
+	// Timings are the request phases durations in milliseconds (-1 means the phase is not applicable).
+	Timings struct {
+		Blocked float64 `json:"blocked"`
+		DNS     float64 `json:"dns"`
+		Connect float64 `json:"connect"` // includes the SSL time
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+		SSL     float64 `json:"ssl"`
+	}
+)
+
+// Recorder is an HTTP transport middleware that records all the requests and responses. Bodies are stored up to the
+// limit (longer ones are truncated). It is safe for concurrent use.
+type Recorder struct {
+	creator     Creator
+	maxBodySize int
+	mu          sync.Mutex
+	entries     []*entry
+}
+
+// truncatedComment is the comment for the truncated bodies.
+const truncatedComment = "the body is truncated"
+
+// NewRecorder creates a new HAR recorder. Zero maxBodySize means that bodies are not stored.
+func NewRecorder(creator Creator, maxBodySize int) *Recorder {
+	return &Recorder{creator: creator, maxBodySize: maxBodySize}
+}
+
+// Wrap wraps the HTTP transport, so all the requests made through it are recorded.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripper(func(req *http.Request) (*http.Response, error) { return r.roundTrip(next, req) })
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+// entry is the entry that is being recorded (the response body is read by the client after the round trip).
+type entry struct {
+	mu    sync.Mutex
+	Entry // guarded by mu
+
+	start, dnsStart, dnsDone, connectStart, connectDone time.Time
+	tlsStart, tlsDone, gotConn, wroteRequest, firstByte time.Time
+	end                                                 time.Time
+}
+
+func (e *entry) set(field *time.Time) {
+	e.mu.Lock()
+	*field = time.Now()
+	e.mu.Unlock()
+}
+
+func (r *Recorder) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	var e = &entry{start: time.Now()}
+
+	e.StartedDateTime = e.start.Format(time.RFC3339Nano)
+	e.Request = Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     cookiePairs(req.Cookies()),
+		Headers:     headerPairs(req.Header),
+		QueryString: make([]Pair, 0),
+		HeadersSize: -1,
+	}
+
+	var query = req.URL.Query()
+
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			e.Request.QueryString = append(e.Request.QueryString, Pair{Name: name, Value: value})
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		e.Request.BodySize = len(body)
+
+		text, _, truncated := r.bodyText(body)
+		e.Request.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: text}
+
+		if truncated {
+			e.Request.PostData.Comment = truncatedComment
+		}
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn:              func(httptrace.GotConnInfo) { e.set(&e.gotConn) },
+		DNSStart:             func(httptrace.DNSStartInfo) { e.set(&e.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { e.set(&e.dnsDone) },
+		ConnectStart:         func(string, string) { e.set(&e.connectStart) },
+		ConnectDone:          func(string, string, error) { e.set(&e.connectDone) },
+		TLSHandshakeStart:    func() { e.set(&e.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { e.set(&e.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { e.set(&e.wroteRequest) },
+		GotFirstResponseByte: func() { e.set(&e.firstByte) },
+	}))
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		e.mu.Lock()
+		e.Response = Response{HTTPVersion: req.Proto, Cookies: []Pair{}, Headers: []Pair{}, HeadersSize: -1}
+		e.Comment = err.Error()
+		e.mu.Unlock()
+
+		e.finish()
+
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.Request.HTTPVersion = resp.Proto // the request protocol is known after the round trip only
+	e.Response = Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     cookiePairs(resp.Cookies()),
+		Headers:     headerPairs(resp.Header),
+		Content:     Content{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+	}
+	e.mu.Unlock()
+
+	resp.Body = &bodyRecorder{ReadCloser: resp.Body, recorder: r, entry: e}
+
+	return resp, nil
+}
+
+// bodyText converts the body to the HAR text (binary content is base64-encoded) and truncates it to the limit.
+func (r *Recorder) bodyText(body []byte) (text, encoding string, truncated bool) {
+	if len(body) > r.maxBodySize {
+		body, truncated = body[:r.maxBodySize], true
+	}
+
+	if utf8.Valid(body) {
+		return string(body), "", truncated
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64", truncated
+}
+
+// bodyRecorder stores the response body (up to the limit) while it is read by the client. The entry is completed
+// when the body is read to the end or closed.
+type bodyRecorder struct {
+	io.ReadCloser
+	recorder *Recorder
+	entry    *entry
+	buf      bytes.Buffer
+	size     int
+	once     sync.Once
+}
+
+func (b *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.size += n
+
+	if rest := b.recorder.maxBodySize - b.buf.Len() + 1; rest > 0 { // +1 to detect the truncation
+		if n < rest {
+			rest = n
+		}
+
+		b.buf.Write(p[:rest])
+	}
+
+	if err != nil {
+		b.complete()
+	}
+
+	return n, err
+}
+
+func (b *bodyRecorder) Close() error {
+	b.complete()
+
+	return b.ReadCloser.Close()
+}
+
+func (b *bodyRecorder) complete() {
+	b.once.Do(func() {
+		var text, encoding, truncated = b.recorder.bodyText(b.buf.Bytes())
+
+		b.entry.mu.Lock()
+		b.entry.Response.BodySize = b.size
+		b.entry.Response.Content.Size = b.size
+		b.entry.Response.Content.Text = text
+		b.entry.Response.Content.Encoding = encoding
+
+		if truncated {
+			b.entry.Response.Content.Comment = truncatedComment
+		}
+		b.entry.mu.Unlock()
+
+		b.entry.finish()
+	})
+}
+
+// finish completes the entry and calculates its timings.
+func (e *entry) finish() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.end.IsZero() {
+		return // already finished
+	}
+
+	e.end = time.Now()
+
+	var sent = e.wroteRequest
+	if sent.IsZero() {
+		sent = e.gotConn
+	}
+
+	e.Timings = Timings{
+		Blocked: -1,
+		DNS:     durationMs(e.dnsStart, e.dnsDone, -1),
+		Connect: durationMs(e.connectStart, e.connectDone, -1),
+		SSL:     durationMs(e.tlsStart, e.tlsDone, -1),
+		Send:    durationMs(e.gotConn, e.wroteRequest, 0),
+		Wait:    durationMs(sent, e.firstByte, 0),
+		Receive: durationMs(e.firstByte, e.end, 0),
+	}
+
+	if !e.tlsDone.IsZero() { // the connect time includes the SSL time
+		e.Timings.Connect = durationMs(e.connectStart, e.tlsDone, -1)
+	}
+
+	e.Time = durationMs(e.start, e.end, 0)
+}
+
+// durationMs returns the duration between the two times in milliseconds (or the fallback if any of them is not set).
+func durationMs(from, to time.Time, fallback float64) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return fallback
+	}
+
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
+
+// Log returns the recorded HAR log.
+func (r *Recorder) Log() Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var log = Log{Version: "1.2", Creator: r.creator, Entries: make([]Entry, 0, len(r.entries))}
+
+	for _, e := range r.entries {
+		e.finish() // for the case when the response body was not read
+
+		e.mu.Lock()
+		log.Entries = append(log.Entries, e.Entry)
+		e.mu.Unlock()
+	}
+
+	return log
+}
+
+// Len returns the number of the recorded entries.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// WriteFile writes the recorded HAR log to the file (it is overwritten, if exists).
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(struct {
+		Log Log `json:"log"`
+	}{Log: r.Log()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644) //nolint:gosec,gomnd // the file should be readable by other tools
+}
+
+// headerPairs converts the headers to the HAR pairs (sensitive values are redacted).
+func headerPairs(h http.Header) []Pair {
+	var pairs = make([]Pair, 0, len(h))
+
+	for _, name := range sortedKeys(h) {
+		for _, value := range h[name] {
+			pairs = append(pairs, Pair{Name: name, Value: redact.HeaderValue(name, value)})
+		}
+	}
+
+	return pairs
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	var keys = make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// cookiePairs converts the cookies to the HAR pairs (values are redacted).
+func cookiePairs(cookies []*http.Cookie) []Pair {
+	var pairs = make([]Pair, 0, len(cookies))
+
+	for _, c := range cookies {
+		pairs = append(pairs, Pair{Name: c.Name, Value: redact.Placeholder}) // the cookie values are the secrets
+	}
+
+	return pairs
+}
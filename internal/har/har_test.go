@@ -0,0 +1,137 @@
+package har_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/har"
+)
+
+func TestRecorder(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/json", http.StatusFound)
+
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			_, _ = w.Write([]byte(`{"foo":"bar"}`))
+
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		recorder = har.NewRecorder(har.Creator{Name: "poke", Version: "1.0.0"}, 8)
+		client   = &http.Client{Transport: recorder.Wrap(http.DefaultTransport)}
+	)
+
+	do := func(req *http.Request) {
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		_, _ = io.ReadAll(resp.Body)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	getReq, _ := http.NewRequest(http.MethodGet, srv.URL+"/redirect?b=2&a=1", http.NoBody)
+	do(getReq)
+
+	binReq, _ := http.NewRequest(http.MethodGet, srv.URL+"/binary", http.NoBody)
+	do(binReq)
+
+	postReq, _ := http.NewRequest(http.MethodPost, srv.URL+"/echo", strings.NewReader("some long request body"))
+	postReq.Header.Set("Content-Type", "text/plain")
+	postReq.Header.Set("Authorization", "Bearer secret")
+	postReq.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	do(postReq)
+
+	_, err := client.Get("http://127.0.0.1:1/") //nolint:bodyclose,noctx
+	require.Error(t, err)
+
+	var log = recorder.Log()
+
+	assert.Equal(t, "1.2", log.Version)
+	assert.Equal(t, har.Creator{Name: "poke", Version: "1.0.0"}, log.Creator)
+	require.Len(t, log.Entries, 5)
+	assert.Equal(t, 5, recorder.Len())
+
+	// redirect
+	var redirect = log.Entries[0]
+	assert.Equal(t, http.MethodGet, redirect.Request.Method)
+	assert.Equal(t, srv.URL+"/redirect?b=2&a=1", redirect.Request.URL)
+	assert.Equal(t, []har.Pair{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, redirect.Request.QueryString)
+	assert.Equal(t, http.StatusFound, redirect.Response.Status)
+	assert.Equal(t, "/json", redirect.Response.RedirectURL)
+	assert.Equal(t, "HTTP/1.1", redirect.Response.HTTPVersion)
+	assert.NotEmpty(t, redirect.StartedDateTime)
+
+	// the redirect target (the body is truncated to 8 bytes)
+	var target = log.Entries[1]
+	assert.Equal(t, srv.URL+"/json", target.Request.URL)
+	assert.Equal(t, http.StatusOK, target.Response.Status)
+	assert.Equal(t, "OK", target.Response.StatusText)
+	assert.Equal(t, []har.Pair{{Name: "session", Value: "[REDACTED]"}}, target.Response.Cookies)
+	assert.Contains(t, target.Response.Headers, har.Pair{Name: "Set-Cookie", Value: "[REDACTED]"})
+	assert.Equal(t, "application/json", target.Response.Content.MimeType)
+	assert.Equal(t, 13, target.Response.Content.Size)
+	assert.Equal(t, `{"foo":"`, target.Response.Content.Text)
+	assert.Equal(t, "the body is truncated", target.Response.Content.Comment)
+	assert.Contains(t, target.Response.Headers, har.Pair{Name: "Content-Type", Value: "application/json"})
+	assert.Greater(t, target.Time, float64(0))
+	assert.Equal(t, float64(-1), target.Timings.SSL)
+
+	// binary content
+	var binary = log.Entries[2]
+	assert.Equal(t, "//4A", binary.Response.Content.Text)
+	assert.Equal(t, "base64", binary.Response.Content.Encoding)
+	assert.Empty(t, binary.Response.Content.Comment)
+
+	// request body
+	var post = log.Entries[3]
+	require.NotNil(t, post.Request.PostData)
+	assert.Equal(t, "text/plain", post.Request.PostData.MimeType)
+	assert.Equal(t, "some lon", post.Request.PostData.Text)
+	assert.Equal(t, 22, post.Request.BodySize)
+	assert.Contains(t, post.Request.Headers, har.Pair{Name: "Authorization", Value: "[REDACTED]"})
+	assert.Contains(t, post.Request.Headers, har.Pair{Name: "Cookie", Value: "[REDACTED]"})
+	assert.Equal(t, []har.Pair{{Name: "session", Value: "[REDACTED]"}}, post.Request.Cookies)
+	assert.Equal(t, 22, post.Response.BodySize) // the body is sent to the server as is
+
+	// failed request
+	var failed = log.Entries[4]
+	assert.Equal(t, 0, failed.Response.Status)
+	assert.Contains(t, failed.Comment, "connection refused")
+
+	// file writing
+	var path = filepath.Join(t.TempDir(), "test.har")
+
+	require.NoError(t, recorder.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var file struct {
+		Log har.Log `json:"log"`
+	}
+
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, log, file.Log)
+}
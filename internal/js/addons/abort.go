@@ -0,0 +1,183 @@
+package addons
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// Abort provides the AbortController and AbortSignal, used to abort the HTTP requests. Since the requests are
+// synchronous (there is no async fetch, because the runtime has no event loop), the controller aborts the requests
+// sent after the abort() call and the streamed bodies, while the AbortSignal.timeout() signal also interrupts the
+// pending requests.
+// https://developer.mozilla.org/en-US/docs/Web/API/AbortController
+type Abort struct {
+	ctx     context.Context
+	runtime *js.Runtime
+}
+
+func NewAbort(ctx context.Context, runtime *js.Runtime) *Abort {
+	return &Abort{ctx: ctx, runtime: runtime}
+}
+
+func (a *Abort) Register(runtime *js.Runtime) error {
+	var signal = runtime.NewObject()
+
+	if err := signal.Set("timeout", a.timeout); err != nil {
+		return err
+	}
+
+	if err := signal.Set("abort", a.aborted); err != nil {
+		return err
+	}
+
+	if err := runtime.GlobalObject().DefineDataProperty(
+		"AbortSignal",
+		signal,
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	); err != nil {
+		return err
+	}
+
+	return runtime.GlobalObject().DefineDataProperty(
+		"AbortController",
+		runtime.ToValue(a.controller),
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	)
+}
+
+// controller is the AbortController constructor.
+func (a *Abort) controller(call js.ConstructorCall) *js.Object {
+	var signal = a.newSignal(0)
+
+	_ = call.This.Set("signal", a.runtime.NewDynamicObject(signal))
+	_ = call.This.Set("abort", func(args ...js.Value) { signal.abort(a.reason(args, "AbortError", "signal is aborted")) })
+
+	return nil
+}
+
+// timeout returns the signal that will abort automatically after the given number of milliseconds.
+func (a *Abort) timeout(ms int64) js.Value {
+	return a.runtime.NewDynamicObject(a.newSignal(time.Duration(ms) * time.Millisecond))
+}
+
+// aborted returns the signal that is already aborted.
+func (a *Abort) aborted(args ...js.Value) js.Value {
+	var signal = a.newSignal(0)
+
+	signal.abort(a.reason(args, "AbortError", "signal is aborted"))
+
+	return a.runtime.NewDynamicObject(signal)
+}
+
+func (a *Abort) reason(args []js.Value, name, message string) js.Value {
+	if len(args) > 0 && !js.IsUndefined(args[0]) {
+		return args[0]
+	}
+
+	return newNamedError(a.runtime, name, message)
+}
+
+// newSignal creates the signal, that will be aborted automatically after the timeout (if it is positive). The
+// signal without the timeout is not derived from the runtime context, so the signals that are never aborted are not
+// retained by it (the requests are canceled with the runtime context anyway).
+func (a *Abort) newSignal(timeout time.Duration) *AbortSignal {
+	var s = &AbortSignal{runtime: a.runtime}
+
+	if timeout > 0 {
+		s.ctx, s.cancel = context.WithTimeout(a.ctx, timeout) // released by the runtime context or the timer
+	} else {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+
+	return s
+}
+
+// AbortSignal is a signal object, that allows to abort the HTTP request. It is exposed to JS as a dynamic object
+// with `aborted`, `reason` properties and the `throwIfAborted` method.
+type AbortSignal struct {
+	runtime *js.Runtime
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu     sync.Mutex
+	reason js.Value // set by the abort() call, the reason for the timeout is created lazily
+}
+
+var _ js.DynamicObject = (*AbortSignal)(nil) // verify that the AbortSignal implements the js.DynamicObject interface
+
+// Context returns the context that is canceled when the signal is aborted.
+func (s *AbortSignal) Context() context.Context { return s.ctx }
+
+func (s *AbortSignal) abort(reason js.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() == nil {
+		s.reason = reason
+		s.cancel()
+	}
+}
+
+// Reason returns the abort reason (undefined if the signal is not aborted).
+func (s *AbortSignal) Reason() js.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reason == nil && s.ctx.Err() != nil {
+		if errors.Is(s.ctx.Err(), context.DeadlineExceeded) {
+			s.reason = newNamedError(s.runtime, "TimeoutError", "signal timed out")
+		} else {
+			s.reason = newNamedError(s.runtime, "AbortError", "signal is aborted")
+		}
+	}
+
+	if s.reason == nil {
+		return js.Undefined()
+	}
+
+	return s.reason
+}
+
+func (s *AbortSignal) Get(key string) js.Value {
+	switch key {
+	case "aborted":
+		return s.runtime.ToValue(s.ctx.Err() != nil)
+
+	case "reason":
+		return s.Reason()
+
+	case "throwIfAborted":
+		return s.runtime.ToValue(func() {
+			if s.ctx.Err() != nil {
+				panic(s.Reason())
+			}
+		})
+	}
+
+	return nil
+}
+
+func (s *AbortSignal) Set(string, js.Value) bool { return false }
+func (s *AbortSignal) Has(key string) bool       { return s.Get(key) != nil }
+func (s *AbortSignal) Delete(string) bool        { return false }
+func (s *AbortSignal) Keys() []string            { return []string{"aborted", "reason"} }
+
+// newNamedError creates the JS Error object with the given name (e.g. `TimeoutError` or `AbortError`).
+func newNamedError(runtime *js.Runtime, name, message string) *js.Object {
+	e, err := runtime.New(runtime.Get("Error"), runtime.ToValue(message))
+	if err != nil {
+		panic(err)
+	}
+
+	_ = e.Set("name", name)
+
+	return e
+}
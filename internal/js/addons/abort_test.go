@@ -0,0 +1,80 @@
+package addons_test
+
+import (
+	"context"
+	"testing"
+
+	js "github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/js/addons"
+)
+
+func TestAbort_Register(t *testing.T) {
+	var (
+		runtime = js.New()
+		addon   = addons.NewAbort(context.Background(), runtime)
+	)
+
+	assert.Nil(t, runtime.Get("AbortController"))
+	assert.Nil(t, runtime.Get("AbortSignal"))
+	assert.NoError(t, addon.Register(runtime))
+	assert.NotNil(t, runtime.Get("AbortController"))
+	assert.NotNil(t, runtime.Get("AbortSignal"))
+}
+
+func TestAbort(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"controller is not aborted": {
+			giveScript: `const c = new AbortController(); [c.signal.aborted, c.signal.reason === undefined]`,
+			wantResult: []any{false, true},
+		},
+		"controller abort": {
+			giveScript: `const c = new AbortController(); c.abort(); [c.signal.aborted, c.signal.reason.name]`,
+			wantResult: []any{true, "AbortError"},
+		},
+		"controller abort with reason": {
+			giveScript: `const c = new AbortController(); c.abort('foo'); c.abort('bar'); c.signal.reason`,
+			wantResult: "foo",
+		},
+		"already aborted signal": {
+			giveScript: `AbortSignal.abort().aborted`,
+			wantResult: true,
+		},
+		"timeout signal": {
+			giveScript: `const s = AbortSignal.timeout(1); const a = s.aborted; process.delay(20)
+				;[a, s.aborted, s.reason.name]`,
+			wantResult: []any{false, true, "TimeoutError"},
+		},
+		"throw if aborted": {
+			giveScript: `AbortSignal.abort(new Error('foo')).throwIfAborted()`,
+			wantError:  "Error: foo",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = js.New()
+
+			runtime.SetFieldNameMapper(js.TagFieldNameMapper("json", true))
+			require.NoError(t, addons.NewAbort(context.Background(), runtime).Register(runtime))
+			require.NoError(t, addons.NewProcess(context.Background(), runtime).Register(runtime))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
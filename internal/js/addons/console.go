@@ -30,7 +30,12 @@ var (
 	typeFunctionCall = reflect.TypeOf((*js.FunctionCall)(nil)) //nolint:gochecknoglobals
 )
 
-func (c *Console) valueToString(v js.Value) string {
+// Stringify converts the JS value to the string in the same way as the console methods do.
+func Stringify(v js.Value) string { return valueToString(jsoniter.ConfigFastest, v) }
+
+func (c *Console) valueToString(v js.Value) string { return valueToString(c.json, v) }
+
+func valueToString(json jsoniter.API, v js.Value) string {
 	if v == nil {
 		return "null"
 	} else if s, ok := v.Export().(string); ok {
@@ -63,7 +68,7 @@ func (c *Console) valueToString(v js.Value) string {
 		return "ƒ(…)"
 
 	default:
-		if j, err := c.json.Marshal(v); err == nil {
+		if j, err := json.Marshal(v); err == nil {
 			return string(j)
 		} else {
 			return fmt.Sprintf("cannot convert passed value to json (%s)", err.Error())
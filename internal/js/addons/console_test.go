@@ -166,3 +166,11 @@ func TestConsole_Register(t *testing.T) {
 	assert.NoError(t, addon.Register(runtime))
 	assert.Same(t, addon, runtime.GlobalObject().Get(name).Export())
 }
+
+func TestStringify(t *testing.T) {
+	var runtime = js.New()
+
+	assert.Equal(t, "undefined", addons.Stringify(js.Undefined()))
+	assert.Equal(t, "foo", addons.Stringify(runtime.ToValue("foo")))
+	assert.Equal(t, `{"foo":[1,2]}`, addons.Stringify(runtime.ToValue(map[string]any{"foo": []int{1, 2}})))
+}
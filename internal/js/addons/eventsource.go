@@ -0,0 +1,571 @@
+package addons
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// EventSource ready states (https://developer.mozilla.org/en-US/docs/Web/API/EventSource/readyState).
+const (
+	sseConnecting = 0
+	sseOpen       = 1
+	sseClosed     = 2
+)
+
+const (
+	sseDefaultReconnectDelay = 3 * time.Second
+	sseMaxLineSize           = 16 << 20 // 16 MiB
+)
+
+// sseEvent is the single server-sent event.
+type sseEvent struct {
+	id, event, data string
+	retry           time.Duration // zero if the retry field is not set
+}
+
+// sseMessage is the message from the connection goroutine to the script (JS) thread.
+type sseMessage struct {
+	open  bool      // the connection is established
+	event *sseEvent // the event is received
+	err   error     // the connection is failed or ended
+	final bool      // no reconnection after the error
+}
+
+// errSSEStreamEnded is reported when the server closes the events stream (the client reconnects).
+var errSSEStreamEnded = errors.New("the events stream is ended")
+
+// EventSource provides the EventSource-like server-sent events client. The HTTP client settings (transport, cookies,
+// defaults and timeout) are shared with the fetch addon.
+// https://developer.mozilla.org/en-US/docs/Web/API/EventSource
+type EventSource struct {
+	ctx   context.Context
+	fetch *Fetch
+}
+
+func NewEventSource(ctx context.Context, fetch *Fetch) *EventSource {
+	return &EventSource{ctx: ctx, fetch: fetch}
+}
+
+// Register registers the EventSource constructor (with the ready state constants).
+func (e *EventSource) Register(runtime *js.Runtime) error {
+	var constructor = runtime.ToValue(e.constructor(runtime)).ToObject(runtime)
+
+	for name, value := range map[string]int{"CONNECTING": sseConnecting, "OPEN": sseOpen, "CLOSED": sseClosed} {
+		if err := constructor.Set(name, value); err != nil {
+			return err
+		}
+	}
+
+	return runtime.GlobalObject().DefineDataProperty(
+		"EventSource",
+		constructor,
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	)
+}
+
+// eventSource is the EventSource-like server-sent events client. The events are received in the background, and the
+// script receives them (and the listeners are called) using the `read()`, `listen()` or `events()` methods.
+// https://developer.mozilla.org/en-US/docs/Web/API/EventSource
+type eventSource struct {
+	f       *Fetch
+	runtime *js.Runtime
+	this    *js.Object
+
+	url            string
+	headers        http.Header
+	timeout        time.Duration // the default waiting timeout (zero means no timeout)
+	reconnect      bool
+	reconnectDelay time.Duration
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	messages  chan sseMessage
+	listeners map[string][]js.Value
+	closed    bool
+}
+
+// constructor is the `new EventSource(url, {headers, timeout, reconnect, reconnectDelay, lastEventId})` constructor.
+func (e *EventSource) constructor(runtime *js.Runtime) func(call js.ConstructorCall) *js.Object {
+	var f = e.fetch
+
+	return func(call js.ConstructorCall) *js.Object {
+		if js.IsUndefined(call.Argument(0)) {
+			panic(runtime.NewTypeError("The EventSource URL is required"))
+		}
+
+		var s = &eventSource{
+			f:              f,
+			runtime:        runtime,
+			this:           call.This,
+			headers:        make(http.Header),
+			timeout:        f.timeout,
+			reconnect:      true,
+			reconnectDelay: sseDefaultReconnectDelay,
+			messages:       make(chan sseMessage),
+			listeners:      make(map[string][]js.Value),
+		}
+
+		for name, values := range f.defaults.Headers {
+			s.headers[name] = append([]string(nil), values...)
+		}
+
+		var lastEventID string
+
+		if arg := call.Argument(1); !js.IsUndefined(arg) && !js.IsNull(arg) {
+			var options = arg.ToObject(runtime)
+
+			if v := options.Get("headers"); v != nil && !js.IsUndefined(v) {
+				for name, values := range headersFromValue(runtime, v) {
+					s.headers[name] = values
+				}
+			}
+
+			if v := options.Get("timeout"); v != nil && !js.IsUndefined(v) {
+				s.timeout = time.Duration(v.ToInteger()) * time.Millisecond
+			}
+
+			if v := options.Get("reconnect"); v != nil && !js.IsUndefined(v) {
+				s.reconnect = v.ToBoolean()
+			}
+
+			if v := options.Get("reconnectDelay"); v != nil && !js.IsUndefined(v) {
+				s.reconnectDelay = time.Duration(v.ToInteger()) * time.Millisecond
+			}
+
+			lastEventID = stringProperty(options, "lastEventId")
+		}
+
+		resolvedURL, err := resolveURL(call.Argument(0).String(), f.defaults.BaseURL, f.defaults.Query, nil)
+		if err != nil {
+			panic(newNetworkError(runtime, errCodeInvalidURL, err))
+		}
+
+		if _, err = http.NewRequest(http.MethodGet, resolvedURL, http.NoBody); err != nil { //nolint:noctx
+			panic(newNetworkError(runtime, errCodeInvalidURL, err))
+		}
+
+		s.url = resolvedURL
+		s.ctx, s.cancel = context.WithCancel(e.ctx)
+
+		for name, value := range map[string]any{
+			"url":                 s.url,
+			"readyState":          sseConnecting,
+			"lastEventId":         lastEventID,
+			"onopen":              js.Null(),
+			"onmessage":           js.Null(),
+			"onerror":             js.Null(),
+			"addEventListener":    s.addEventListener,
+			"removeEventListener": s.removeEventListener,
+			"read":                s.read,
+			"listen":              s.listen,
+			"events":              s.events,
+			"close":               s.close,
+		} {
+			_ = call.This.Set(name, value)
+		}
+
+		_ = call.This.SetSymbol(js.SymIterator, func(js.FunctionCall) js.Value { return s.events(js.FunctionCall{}) })
+
+		go s.run(lastEventID)
+
+		return nil
+	}
+}
+
+// addEventListener adds the listener for the event type (`open`, `error`, `message` or any custom event name).
+func (s *eventSource) addEventListener(eventType string, listener js.Value) {
+	if _, ok := js.AssertFunction(listener); !ok {
+		panic(s.runtime.NewTypeError("The listener must be a function"))
+	}
+
+	s.listeners[eventType] = append(s.listeners[eventType], listener)
+}
+
+// removeEventListener removes the listener, added using the addEventListener.
+func (s *eventSource) removeEventListener(eventType string, listener js.Value) {
+	var listeners = s.listeners[eventType][:0]
+
+	for _, l := range s.listeners[eventType] {
+		if !l.SameAs(listener) {
+			listeners = append(listeners, l)
+		}
+	}
+
+	s.listeners[eventType] = listeners
+}
+
+// read waits for the next event (up to the timeout in milliseconds, the `timeout` option is used by default) and
+// returns it, or null if the timeout is exceeded or the connection is closed.
+func (s *eventSource) read(call js.FunctionCall) js.Value {
+	var deadline = s.deadline(call.Argument(0))
+
+	for {
+		msg, ok := s.receive(deadline)
+		if !ok {
+			return js.Null()
+		}
+
+		if event := s.process(msg); event != nil {
+			return event
+		}
+	}
+}
+
+// listen receives the events (and calls the listeners) until the timeout in milliseconds is exceeded (the `timeout`
+// option is used by default) or the connection is closed. The number of the received events is returned.
+func (s *eventSource) listen(call js.FunctionCall) js.Value {
+	var (
+		deadline = s.deadline(call.Argument(0))
+		count    int
+	)
+
+	for {
+		msg, ok := s.receive(deadline)
+		if !ok {
+			return s.runtime.ToValue(count)
+		}
+
+		if event := s.process(msg); event != nil {
+			count++
+		}
+	}
+}
+
+// events returns the iterator over the events (each one is waited up to the timeout in milliseconds, the `timeout`
+// option is used by default). The iteration stops on timeout or when the connection is closed.
+func (s *eventSource) events(call js.FunctionCall) js.Value {
+	var (
+		iterator = s.runtime.NewObject()
+		timeout  = call.Argument(0)
+	)
+
+	_ = iterator.Set("next", func(js.FunctionCall) js.Value {
+		var (
+			result = s.runtime.NewObject()
+			event  = s.read(js.FunctionCall{Arguments: []js.Value{timeout}})
+		)
+
+		_ = result.Set("done", js.IsNull(event))
+
+		if !js.IsNull(event) {
+			_ = result.Set("value", event)
+		}
+
+		return result
+	})
+
+	_ = iterator.SetSymbol(js.SymIterator, func(call js.FunctionCall) js.Value { return call.This })
+
+	return iterator
+}
+
+// close closes the connection (no reconnection is made).
+func (s *eventSource) close() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.cancel()
+
+	_ = s.this.Set("readyState", sseClosed)
+}
+
+// deadline returns the waiting deadline for the timeout argument (zero time means no deadline).
+func (s *eventSource) deadline(timeout js.Value) time.Time {
+	var d = s.timeout
+
+	if !js.IsUndefined(timeout) && !js.IsNull(timeout) {
+		d = time.Duration(timeout.ToInteger()) * time.Millisecond
+	}
+
+	if d <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(d)
+}
+
+// receive waits for the next message from the connection goroutine until the deadline. False is returned on
+// timeout, or when the connection (or the script execution) is closed.
+func (s *eventSource) receive(deadline time.Time) (sseMessage, bool) {
+	if s.closed {
+		return sseMessage{}, false
+	}
+
+	var timeout <-chan time.Time
+
+	if !deadline.IsZero() {
+		var timer = time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case msg, ok := <-s.messages:
+		if !ok {
+			s.close()
+		}
+
+		return msg, ok
+
+	case <-timeout:
+		return sseMessage{}, false
+
+	case <-s.ctx.Done():
+		s.close()
+
+		return sseMessage{}, false
+	}
+}
+
+// process applies the message to the EventSource state and calls the listeners. The JS event object is returned
+// for the received events (nil for the connection state changes).
+func (s *eventSource) process(msg sseMessage) js.Value {
+	switch {
+	case msg.open:
+		_ = s.this.Set("readyState", sseOpen)
+
+		s.dispatch("open", s.runtime.NewObject())
+
+	case msg.err != nil:
+		var event = s.runtime.NewObject()
+
+		_ = event.Set("event", "error")
+		_ = event.Set("message", msg.err.Error())
+
+		if msg.final {
+			s.close()
+		} else {
+			_ = s.this.Set("readyState", sseConnecting)
+		}
+
+		s.dispatch("error", event)
+
+	case msg.event != nil:
+		var event = s.runtime.NewObject()
+
+		_ = event.Set("id", msg.event.id)
+		_ = event.Set("event", msg.event.event)
+		_ = event.Set("data", msg.event.data)
+		_ = event.Set("retry", msg.event.retry.Milliseconds())
+		_ = s.this.Set("lastEventId", msg.event.id)
+
+		s.dispatch(msg.event.event, event)
+
+		return event
+	}
+
+	return nil
+}
+
+// dispatch calls the `on<type>` handler and the listeners of the event type. The listener exceptions are thrown.
+func (s *eventSource) dispatch(eventType string, event *js.Object) {
+	if event.Get("event") == nil {
+		_ = event.Set("event", eventType)
+	}
+
+	var listeners = append([]js.Value{}, s.listeners[eventType]...)
+
+	if handler := s.this.Get("on" + eventType); handler != nil {
+		if _, ok := js.AssertFunction(handler); ok {
+			listeners = append([]js.Value{handler}, listeners...)
+		}
+	}
+
+	for _, listener := range listeners {
+		fn, _ := js.AssertFunction(listener)
+
+		if _, err := fn(s.this, event); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// run connects to the server and sends the received events to the script thread. The connection is re-established
+// (with the Last-Event-ID header) when the stream is ended or the network error is occurred, until it is closed.
+func (s *eventSource) run(lastEventID string) {
+	defer close(s.messages)
+
+	var delay = s.reconnectDelay
+
+	for {
+		final, err := s.connect(&lastEventID, &delay)
+
+		if !s.send(sseMessage{err: err, final: final || !s.reconnect}) || final || !s.reconnect {
+			return
+		}
+
+		var timer = time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+
+			return
+		}
+	}
+}
+
+// send sends the message to the script thread (false is returned if the EventSource is closed).
+func (s *eventSource) send(msg sseMessage) bool {
+	select {
+	case s.messages <- msg:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// connect establishes the connection and reads the events stream. The final flag is set for the errors that must
+// not be followed by the reconnection (unexpected status code or content type).
+func (s *eventSource) connect(lastEventID *string, delay *time.Duration) (bool, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.url, http.NoBody)
+	if err != nil {
+		return true, err
+	}
+
+	req.Header = s.headers.Clone()
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
+
+	var client = s.f.client(requestOptions{ //nolint:gomnd
+		withCookies:  true,
+		redirect:     redirectFollow,
+		maxRedirects: 10,
+		debug:        s.f.debug,
+	}, &[]fetchRedirect{})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return true, fmt.Errorf("unexpected response status code %d", resp.StatusCode)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return true, fmt.Errorf("unexpected response content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	if !s.send(sseMessage{open: true}) {
+		return true, context.Canceled
+	}
+
+	var (
+		scanner = bufio.NewScanner(resp.Body)
+		event   sseEvent
+		data    strings.Builder
+		first   = true
+	)
+
+	scanner.Buffer(make([]byte, 0, 4096), sseMaxLineSize) //nolint:gomnd
+	scanner.Split(scanSSELines)
+
+	for scanner.Scan() {
+		var line = scanner.Text()
+
+		if first { // the UTF-8 BOM is ignored
+			line, first = strings.TrimPrefix(line, "\ufeff"), false
+		}
+
+		if line == "" { // dispatch the event
+			if data.Len() > 0 {
+				var dispatched = event
+
+				dispatched.id, dispatched.data = *lastEventID, strings.TrimSuffix(data.String(), "\n")
+
+				if dispatched.event == "" {
+					dispatched.event = "message"
+				}
+
+				if !s.send(sseMessage{event: &dispatched}) {
+					return true, context.Canceled
+				}
+			}
+
+			event, first = sseEvent{}, false
+			data.Reset()
+
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") { // comment
+			continue
+		}
+
+		var field, value, _ = strings.Cut(line, ":")
+
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.event = value
+
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				*lastEventID = value
+			}
+
+		case "retry":
+			if ms, parseErr := strconv.ParseUint(value, 10, 32); parseErr == nil {
+				event.retry = time.Duration(ms) * time.Millisecond
+				*delay = event.retry
+			}
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return false, err
+	}
+
+	return false, errSSEStreamEnded
+}
+
+// scanSSELines is the bufio.SplitFunc for the event stream lines, that can be ended with CRLF, LF or CR.
+func scanSSELines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\r' {
+			if i+1 == len(data) && !atEOF {
+				return 0, nil, nil // more data is needed to check for the CRLF
+			}
+
+			if i+1 < len(data) && data[i+1] == '\n' {
+				return i + 2, data[:i], nil //nolint:gomnd
+			}
+		}
+
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
@@ -0,0 +1,211 @@
+package addons_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	js "github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/js/addons"
+)
+
+func TestEventSource_Register(t *testing.T) {
+	var (
+		runtime = js.New()
+		addon   = addons.NewEventSource(context.Background(), addons.NewFetch(context.Background()))
+	)
+
+	assert.Nil(t, runtime.Get("EventSource"))
+	assert.NoError(t, addon.Register(runtime))
+	assert.NotNil(t, runtime.Get("EventSource"))
+	assert.Equal(t, int64(1), runtime.Get("EventSource").ToObject(runtime).Get("OPEN").Export())
+}
+
+func TestEventSource(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		lastEventIDs []string
+	)
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+
+			for _, chunk := range []string{
+				": comment\n\n",
+				"data: first\n\n",
+				"id: 2\r\nevent: update\r\ndata: multi\r\ndata: line\r\n\r\n",
+				"retry: 1500\nid: 3\ndata:no space\n\n",
+			} {
+				_, _ = w.Write([]byte(chunk))
+				w.(http.Flusher).Flush()
+			}
+
+			<-r.Context().Done() // keep the connection open
+
+		case "/reconnect": // ends the stream after each event
+			mu.Lock()
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			var n = len(lastEventIDs)
+			mu.Unlock()
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(fmt.Sprintf("retry: 1\nid: %d\ndata: event %d\n\n", n, n)))
+
+		case "/wrong":
+			_, _ = w.Write([]byte("not an event stream"))
+		}
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+		wantIDs    []string
+	}{
+		"read events": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/events'), events = [];
+
+				for (let i = 0; i < 3; i++) {
+					const e = es.read(1000);
+
+					events.push([e.id, e.event, e.data, e.retry]);
+				}
+
+				events.push(es.readyState === EventSource.OPEN, es.lastEventId);
+				es.close();
+				events.push(es.readyState === EventSource.CLOSED, es.read(10));
+
+				events
+			}`,
+			wantResult: []any{
+				[]any{"", "message", "first", int64(0)},
+				[]any{"2", "update", "multi\nline", int64(0)},
+				[]any{"3", "message", "no space", int64(1500)},
+				true, "3", true, nil,
+			},
+		},
+		"listeners": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/events'), calls = [], onUpdate = (e) => calls.push('removed');
+
+				es.onopen = () => calls.push('open');
+				es.onmessage = (e) => calls.push('message: ' + e.data);
+				es.addEventListener('update', (e) => calls.push('update: ' + e.data));
+				es.addEventListener('update', onUpdate);
+				es.removeEventListener('update', onUpdate);
+
+				calls.push(es.listen(300));
+				es.close();
+
+				calls
+			}`,
+			wantResult: []any{"open", "message: first", "update: multi\nline", "message: no space", int64(3)},
+		},
+		"iterator with timeout": {
+			giveScript: `{
+				const data = [];
+
+				for (const e of new EventSource(srvURL + '/events', {timeout: 200})) {
+					data.push(e.data);
+				}
+
+				data
+			}`,
+			wantResult: []any{"first", "multi\nline", "no space"},
+		},
+		"reconnection": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/reconnect', {lastEventId: '0'}), errors = [];
+
+				es.onerror = (e) => errors.push(e.message);
+
+				const data = [es.read(1000).data, es.read(1000).data, es.read(1000).data];
+
+				es.close();
+
+				[data, errors.length >= 2, errors[0]]
+			}`,
+			wantResult: []any{[]any{"event 1", "event 2", "event 3"}, true, "the events stream is ended"},
+			wantIDs:    []string{"0", "1", "2"},
+		},
+		"no reconnection": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/reconnect', {reconnect: false});
+
+				[es.read(1000).data, es.read(1000), es.readyState === EventSource.CLOSED]
+			}`,
+			wantResult: []any{"event 1", nil, true},
+		},
+		"wrong content type": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/wrong');
+				let message;
+
+				es.addEventListener('error', (e) => { message = e.message });
+
+				[es.read(1000), es.readyState, message]
+			}`,
+			wantResult: []any{nil, int64(2), "unexpected response content type text/plain; charset=utf-8"},
+		},
+		"listener exception": {
+			giveScript: `{
+				const es = new EventSource(srvURL + '/events');
+
+				es.onmessage = () => { throw new Error('foo') };
+				es.listen(1000);
+			}`,
+			wantError: "Error: foo",
+		},
+		"wrong url": {
+			giveScript: `new EventSource('http://[::1')`,
+			wantError:  "EINVALIDURL",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			lastEventIDs = nil
+			mu.Unlock()
+
+			var (
+				ctx, cancel = context.WithCancel(context.Background())
+				runtime     = js.New()
+			)
+
+			defer cancel() // not closed connections are closed with the context
+
+			runtime.SetFieldNameMapper(js.TagFieldNameMapper("json", true))
+			require.NoError(t, addons.NewEventSource(ctx, addons.NewFetch(ctx)).Register(runtime))
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+
+			if tt.wantIDs != nil {
+				mu.Lock()
+				assert.Equal(t, tt.wantIDs, lastEventIDs[:len(tt.wantIDs)])
+				mu.Unlock()
+			}
+		})
+	}
+}
@@ -3,38 +3,206 @@ package addons
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	js "github.com/dop251/goja"
+
+	"github.com/tarampampam/poke/internal/log"
+)
+
+type (
+	// FetchOption allows to set up some internal Fetch properties from outside.
+	FetchOption func(*Fetch)
+
+	Fetch struct {
+		ctx       context.Context
+		transport http.RoundTripper
+		jar       *cookieJar
+		timeout   time.Duration
+		defaults  FetchDefaults
+		log       log.Logger
+		debug     bool // log all the requests and responses
+
+		oauth2Mu      sync.Mutex
+		oauth2Clients map[oauth2Config]*OAuth2Client
+
+		protocolTransportsMu sync.Mutex
+		protocolTransports   map[string]http.RoundTripper // shared transports with the forced protocol
+
+		middlewares []func(http.RoundTripper) http.RoundTripper
+	}
 )
 
-type httpClient interface {
-	Do(req *http.Request) (*http.Response, error)
+// WithFetchTransport sets up the HTTP transport for the requests sending.
+func WithFetchTransport(rt http.RoundTripper) FetchOption {
+	return func(f *Fetch) { f.transport = rt }
 }
 
-type Fetch struct {
-	ctx    context.Context
-	client httpClient
+// WithFetchMiddleware adds the HTTP transport middleware (e.g. for the traffic recording). It wraps the transport of
+// each request, including the ones with the per-request transport options.
+func WithFetchMiddleware(mw func(http.RoundTripper) http.RoundTripper) FetchOption {
+	return func(f *Fetch) { f.middlewares = append(f.middlewares, mw) }
 }
 
-func NewFetch(ctx context.Context, client httpClient) *Fetch {
-	const defaultTimeout = time.Second * 60
+// WithFetchTimeout sets up the default timeout for the requests (zero means no timeout).
+func WithFetchTimeout(timeout time.Duration) FetchOption {
+	return func(f *Fetch) { f.timeout = timeout }
+}
 
-	if client == nil {
-		client = &http.Client{
-			Timeout: defaultTimeout,
+// WithFetchTLSConfig sets up the TLS config for the requests (it is applied to the HTTP transport, so it must be
+// used after the WithFetchTransport option).
+func WithFetchTLSConfig(cfg *tls.Config) FetchOption {
+	return func(f *Fetch) {
+		if t, ok := f.transport.(*http.Transport); ok {
+			t.TLSClientConfig = cfg
 		}
 	}
+}
+
+func NewFetch(ctx context.Context, options ...FetchOption) *Fetch {
+	const defaultTimeout = time.Second * 60
+
+	var f = &Fetch{ // defaults
+		ctx:       ctx,
+		transport: http.DefaultTransport.(*http.Transport).Clone(),
+		jar:       newCookieJar(),
+		timeout:   defaultTimeout,
+		defaults:  FetchDefaults{Headers: make(http.Header), Query: make(url.Values)},
+		log:       log.NewNop(),
+
+		oauth2Clients:      make(map[oauth2Config]*OAuth2Client),
+		protocolTransports: make(map[string]http.RoundTripper),
+	}
+
+	for _, opt := range options {
+		opt(f)
+	}
 
-	return &Fetch{ctx: ctx, client: client}
+	return f
 }
 
 func (f *Fetch) Register(runtime *js.Runtime) error {
-	return runtime.Set("fetchSync", f.fetch(runtime))
+	if err := runtime.Set("fetchSync", f.fetch(runtime)); err != nil {
+		return err
+	}
+
+	if err := runtime.GlobalObject().DefineDataProperty(
+		"Headers",
+		runtime.ToValue(headersConstructor(runtime)),
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	); err != nil {
+		return err
+	}
+
+	if err := runtime.GlobalObject().DefineDataProperty(
+		"cookies",
+		runtime.ToValue(&Cookies{runtime: runtime, jar: f.jar}),
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	); err != nil {
+		return err
+	}
+
+	var httpObject = runtime.NewObject()
+
+	if err := httpObject.Set("defaults", f.defaultsHandler(runtime)); err != nil {
+		return err
+	}
+
+	if err := httpObject.Set("oauth2", f.oauth2Handler(runtime)); err != nil {
+		return err
+	}
+
+	return runtime.GlobalObject().DefineDataProperty(
+		"http",
+		httpObject,
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	)
+}
+
+// Redirect modes (https://developer.mozilla.org/en-US/docs/Web/API/fetch#redirect).
+const (
+	redirectFollow = "follow" // automatically follow redirects (default)
+	redirectManual = "manual" // return the redirect response as is
+	redirectError  = "error"  // abort with an error if a redirect occurs
+)
+
+// requestOptions are the HTTP client options for the single request.
+type requestOptions struct {
+	withCookies  bool
+	redirect     string
+	maxRedirects int
+	transport    http.RoundTripper // overrides the default transport, if set
+	debug        bool              // log the requests and responses
+	auth         *requestAuth
+}
+
+// client creates the HTTP client for the single request. Redirects are appended to the chain.
+func (f *Fetch) client(o requestOptions, chain *[]fetchRedirect) *http.Client {
+	var client = &http.Client{Transport: f.transport}
+
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+
+	for _, mw := range f.middlewares {
+		client.Transport = mw(client.Transport)
+	}
+
+	if o.auth != nil && o.auth.kind == authDigest {
+		client.Transport = &digestTransport{
+			next:     client.Transport,
+			host:     o.auth.host,
+			username: o.auth.username,
+			password: o.auth.password,
+		}
+	}
+
+	if o.debug {
+		client.Transport = debugTransport{next: client.Transport, log: f.log}
+	}
+
+	if o.withCookies {
+		client.Jar = f.jar
+	}
+
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		switch {
+		case o.redirect == redirectManual:
+			return http.ErrUseLastResponse // the redirect response is returned as is (and not added to the chain)
+
+		case o.redirect == redirectError:
+			return fmt.Errorf("%w: redirect to %s (redirect mode is %s)", errRedirect, req.URL, redirectError)
+
+		case len(via) > o.maxRedirects:
+			return fmt.Errorf("%w: stopped after %d redirects", errRedirect, o.maxRedirects)
+		}
+
+		if req.Response != nil && req.Response.Request != nil { // only the followed redirects are added
+			*chain = append(*chain, fetchRedirect{
+				URL:    req.Response.Request.URL.String(),
+				Status: req.Response.StatusCode,
+			})
+		}
+
+		return nil
+	}
+
+	return client
 }
 
 // https://developer.mozilla.org/en-US/docs/Web/API/Fetch_API/Using_Fetch
@@ -45,7 +213,7 @@ func (f *Fetch) fetch(runtime *js.Runtime) func(call js.FunctionCall) js.Value {
 		}
 
 		var (
-			url     = call.Argument(0).String()
+			rawURL  = call.Argument(0).String()
 			options *js.Object
 		)
 
@@ -59,84 +227,408 @@ func (f *Fetch) fetch(runtime *js.Runtime) func(call js.FunctionCall) js.Value {
 			method            = http.MethodGet
 			headers           = make(http.Header)
 			body    io.Reader = http.NoBody
+			reqOpts           = requestOptions{
+				withCookies:  true,
+				redirect:     redirectFollow,
+				maxRedirects: 10, //nolint:gomnd
+				debug:        f.debug,
+			}
+			timeout     = f.timeout
+			signal      *AbortSignal
+			stream      bool  // the response body is not read, but streamed
+			maxBodySize int64 // zero means no limit
 		)
 
 		headers.Set("User-Agent", "Mozilla/5.0 (X11) Gecko/20100101 Firefox/106.0") // default user-agent
 
+		for name, values := range f.defaults.Headers {
+			headers[name] = append([]string(nil), values...)
+		}
+
 		if methodValue := options.Get("method"); methodValue != nil {
 			method = strings.ToUpper(methodValue.String())
 		}
 
-		if headersValue := options.Get("headers"); headersValue != nil {
-			if headersMap, isMap := headersValue.Export().(map[string]any); isMap {
-				for name, headerValue := range headersMap {
-					if value, isString := headerValue.(string); isString {
-						headers.Set(name, value)
-					}
-				}
+		if headersValue := options.Get("headers"); headersValue != nil && !js.IsUndefined(headersValue) {
+			for name, values := range headersFromValue(runtime, headersValue) {
+				headers[name] = values // request headers replace the defaults
+			}
+		}
+
+		var query url.Values
+
+		if queryValue := options.Get("query"); queryValue != nil && !js.IsUndefined(queryValue) && !js.IsNull(queryValue) {
+			var queryObject = queryValue.ToObject(runtime)
+
+			query = make(url.Values)
+
+			for _, name := range queryObject.Keys() {
+				query[name] = queryValues(runtime, queryObject.Get(name))
 			}
 		}
 
 		if bodyValue := options.Get("body"); bodyValue != nil {
-			body = bytes.NewBufferString(bodyValue.String())
+			var contentType string
+
+			if body, contentType = requestBody(runtime, bodyValue); contentType != "" && headers.Get("Content-Type") == "" {
+				headers.Set("Content-Type", contentType)
+			}
+		}
+
+		if credentialsValue := options.Get("credentials"); credentialsValue != nil {
+			reqOpts.withCookies = credentialsValue.String() != "omit"
+		}
+
+		if redirectValue := options.Get("redirect"); redirectValue != nil {
+			switch mode := redirectValue.String(); mode {
+			case redirectFollow, redirectManual, redirectError:
+				reqOpts.redirect = mode
+			default:
+				panic(runtime.NewTypeError("Unsupported redirect mode: " + mode))
+			}
+		}
+
+		if value := options.Get("maxRedirects"); value != nil && !js.IsUndefined(value) && !js.IsNull(value) {
+			reqOpts.maxRedirects = int(value.ToInteger())
+		}
+
+		if authValue := options.Get("auth"); authValue != nil && !js.IsUndefined(authValue) && !js.IsNull(authValue) {
+			reqOpts.auth = f.parseAuth(runtime, authValue)
+		}
+
+		if debugValue := options.Get("debug"); debugValue != nil && !js.IsUndefined(debugValue) {
+			reqOpts.debug = debugValue.ToBoolean()
+		}
+
+		if value := options.Get("timeout"); value != nil && !js.IsUndefined(value) && !js.IsNull(value) {
+			timeout = time.Duration(value.ToInteger()) * time.Millisecond
+		}
+
+		if signalValue := options.Get("signal"); signalValue != nil && !js.IsUndefined(signalValue) {
+			var isSignal bool
+
+			if signal, isSignal = signalValue.Export().(*AbortSignal); !isSignal {
+				panic(runtime.NewTypeError("The signal option must be an AbortSignal"))
+			}
+
+			if signal.Context().Err() != nil {
+				panic(signal.Reason()) // the request is not sent if the signal is already aborted
+			}
+		}
+
+		if streamValue := options.Get("stream"); streamValue != nil && !js.IsUndefined(streamValue) {
+			stream = streamValue.ToBoolean()
+		}
+
+		if maxBodySizeValue := options.Get("maxBodySize"); maxBodySizeValue != nil && !js.IsUndefined(maxBodySizeValue) {
+			if maxBodySize = maxBodySizeValue.ToInteger(); maxBodySize < 0 {
+				panic(runtime.NewTypeError("The maxBodySize option must not be negative"))
+			}
+		}
+
+		var transportOpts transportOptions
+
+		if tlsValue := options.Get("tls"); tlsValue != nil && !js.IsUndefined(tlsValue) {
+			transportOpts.tls = parseTLSOptions(runtime, tlsValue.ToObject(runtime))
+		}
+
+		if proxyValue := options.Get("proxy"); proxyValue != nil && !js.IsUndefined(proxyValue) {
+			var proxy string // false, null and empty string disable the proxy
+
+			if !js.IsNull(proxyValue) && proxyValue.ToBoolean() {
+				proxy = proxyValue.String()
+			}
+
+			transportOpts.proxy = &proxy
+		}
+
+		if protocolValue := options.Get("protocol"); protocolValue != nil && !js.IsUndefined(protocolValue) {
+			switch protocol := protocolValue.String(); protocol {
+			case protocolHTTP1, protocolH2, protocolH2C:
+				transportOpts.protocol = protocol
+			default:
+				panic(runtime.NewTypeError("Unsupported protocol: " + protocol + " (http1, h2 or h2c are supported)"))
+			}
+		}
+
+		if socketValue := options.Get("socketPath"); socketValue != nil && !js.IsUndefined(socketValue) {
+			transportOpts.socketPath = socketValue.String()
+		}
+
+		var retry = retryOptions{attempts: 1}
+
+		if retryValue := options.Get("retry"); retryValue != nil && !js.IsUndefined(retryValue) && !js.IsNull(retryValue) {
+			retry = parseRetryOptions(runtime, retryValue)
 		}
 
 		var result = fetchResponse{
-			runtime: runtime,
-			Headers: make(map[string]string),
-			URL:     url,
+			runtime:   runtime,
+			Headers:   newHeaders(runtime, nil).object(),
+			URL:       rawURL,
+			Redirects: make([]fetchRedirect, 0),
 		}
 
-		req, err := http.NewRequestWithContext(f.ctx, method, url, body)
+		resolvedURL, err := resolveURL(rawURL, f.defaults.BaseURL, f.defaults.Query, query)
 		if err != nil {
-			result.setStatusCode(http.StatusInternalServerError)
-			result.Body = err.Error()
+			panic(newNetworkError(runtime, errCodeInvalidURL, err))
+		}
 
-			return runtime.ToValue(result)
+		if socketPath, httpURL := unixSocketURL(resolvedURL); socketPath != "" {
+			transportOpts.socketPath, resolvedURL = socketPath, httpURL
 		}
 
-		req.Header = headers
+		result.URL = resolvedURL
+
+		var release = func() {} // releases the per-request transport (the streamed body does it on close)
+
+		if !transportOpts.isEmpty() {
+			transport, releaseTransport, transportErr := f.requestTransport(transportOpts)
+			if transportErr != nil {
+				panic(runtime.NewTypeError("Cannot create the HTTP transport: " + transportErr.Error()))
+			}
+
+			reqOpts.transport, release = transport, releaseTransport
+		}
 
-		req.UserAgent()
+		defer func() { release() }()
 
-		resp, err := f.client.Do(req)
+		probe, err := http.NewRequest(method, resolvedURL, http.NoBody) //nolint:noctx // the URL and method validation
 		if err != nil {
-			result.setStatusCode(http.StatusInternalServerError)
-			result.Body = err.Error()
+			panic(newNetworkError(runtime, errCodeInvalidURL, err))
+		}
+
+		switch {
+		case transportOpts.protocol == protocolH2 && probe.URL.Scheme != "https":
+			panic(runtime.NewTypeError("The h2 protocol requires the https:// URL (use h2c for the plain HTTP/2)"))
+
+		case transportOpts.protocol == protocolH2C && probe.URL.Scheme != "http":
+			panic(runtime.NewTypeError("The h2c protocol requires the http:// URL (use h2 for the HTTP/2 over TLS)"))
+		}
+
+		if reqOpts.auth != nil {
+			reqOpts.auth.host = probe.URL.Host
+			reqOpts.auth.apply(headers)
+		}
+
+		var bodyBytes []byte // the body is read once, to be sent again on retries
 
-			return runtime.ToValue(result)
+		if retry.attempts > 1 && body != http.NoBody {
+			if bodyBytes, err = io.ReadAll(body); err != nil {
+				panic(runtime.NewTypeError("Cannot read the request body: " + err.Error()))
+			}
 		}
 
-		defer func() { _ = resp.Body.Close() }()
+		for result.Attempts = 1; ; result.Attempts++ {
+			if bodyBytes != nil {
+				body = bytes.NewReader(bodyBytes)
+			}
+
+			result.Redirects = make([]fetchRedirect, 0)
+
+			var ctx, cancel = f.requestContext(timeout, signal)
+
+			resp, responseBody, tracer, sendErr := f.send(ctx, f.client(reqOpts, &result.Redirects), method, resolvedURL,
+				headers, body, stream, maxBodySize,
+			)
 
-		responseBody, _ := io.ReadAll(resp.Body)
+			if !stream || sendErr != nil {
+				cancel()
+			}
+
+			if sendErr != nil {
+				if result.Attempts < retry.attempts && retry.retryNetworkError(sendErr) && f.ctx.Err() == nil &&
+					(signal == nil || signal.Context().Err() == nil) {
+					f.sleep(runtime, retry.delay(result.Attempts, nil), signal)
+
+					continue
+				}
+
+				f.throwError(ctx, runtime, sendErr, timeout, signal)
+			}
+
+			if result.Attempts < retry.attempts && retry.retryStatus(resp.StatusCode) {
+				if stream {
+					_ = resp.Body.Close()
+					cancel()
+				}
+
+				f.sleep(runtime, retry.delay(result.Attempts, resp.Header), signal)
+
+				continue
+			}
 
-		result.setStatusCode(resp.StatusCode)
-		result.Body = string(responseBody)
-		result.OK = resp.StatusCode >= 200 && resp.StatusCode < 300 //nolint:gomnd
+			result.setStatusCode(resp.StatusCode)
+			result.contentType = resp.Header.Get("Content-Type")
 
-		for name, v := range resp.Header {
-			result.Headers[name] = strings.Join(v, ", ")
+			if stream {
+				result.stream = newFetchBodyStream(f, runtime, ctx, resp.Body, maxBodySize, cancel, release)
+				result.stream.timeout, result.stream.signal = timeout, signal
+				result.stream.contentType = result.contentType
+				release = func() {} // the transport is released when the stream is closed
+			} else {
+				result.raw = responseBody
+			}
+			result.OK = resp.StatusCode >= 200 && resp.StatusCode < 300 //nolint:gomnd
+			result.URL = resp.Request.URL.String()
+			result.Redirected = len(result.Redirects) > 0 && reqOpts.redirect == redirectFollow
+			result.TLS = newFetchTLS(resp.TLS)
+			result.Timings = tracer.Timings()
+			result.Connection = tracer.Connection()
+			result.Protocol = responseProtocol(resp)
+
+			result.header = resp.Header
+			result.Headers = newHeaders(runtime, resp.Header).object()
+
+			return result.object()
 		}
+	}
+}
 
-		return runtime.ToValue(result)
+// send sends the request and reads the response body (limited by the maximum size, if it is positive). When the
+// body is streamed, it is not read (and must be closed by the caller).
+func (f *Fetch) send(
+	ctx context.Context,
+	client *http.Client,
+	method, rawURL string,
+	headers http.Header,
+	body io.Reader,
+	stream bool,
+	maxBodySize int64,
+) (*http.Response, []byte, *timingsTracer, error) {
+	var tracer = newTimingsTracer()
+
+	req, err := http.NewRequestWithContext(tracer.WithContext(ctx), method, rawURL, body)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+
+	req.Header = headers.Clone()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if maxBodySize > 0 && resp.ContentLength > maxBodySize {
+		_ = resp.Body.Close()
+
+		return nil, nil, nil, fmt.Errorf("%w (%d bytes, the limit is %d)", errBodyTooLarge, resp.ContentLength, maxBodySize)
+	}
+
+	if stream {
+		tracer.Done() // the body downloading is not measured
+
+		return resp, nil, tracer, nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	responseBody, err := readBody(resp.Body, maxBodySize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tracer.Done()
+
+	return resp, responseBody, tracer, nil
+}
+
+// sleep pauses the execution before the retry. The signal reason is thrown if the signal is aborted, and the
+// network error is thrown if the script execution is interrupted.
+func (f *Fetch) sleep(runtime *js.Runtime, d time.Duration, signal *AbortSignal) {
+	var (
+		timer = time.NewTimer(d)
+		abort <-chan struct{}
+	)
+
+	defer timer.Stop()
+
+	if signal != nil {
+		abort = signal.Context().Done()
+	}
+
+	select {
+	case <-timer.C:
+	case <-abort:
+		panic(signal.Reason())
+	case <-f.ctx.Done():
+		panic(newNetworkError(runtime, errCodeCanceled, f.ctx.Err()))
+	}
+}
+
+// requestContext creates the context for the single request, that is canceled on timeout (if it is positive) or
+// when the signal is aborted.
+func (f *Fetch) requestContext(timeout time.Duration, signal *AbortSignal) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(f.ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(f.ctx)
+	}
+
+	if signal != nil {
+		go func() {
+			select {
+			case <-signal.Context().Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, cancel
+}
+
+// throwError throws the JS error for the failed request: the signal reason if the request was aborted by the
+// signal, the `TimeoutError` if the request timed out, or the network error (TypeError with the error code).
+func (f *Fetch) throwError(
+	ctx context.Context,
+	runtime *js.Runtime,
+	err error,
+	timeout time.Duration,
+	signal *AbortSignal,
+) {
+	if signal != nil && signal.Context().Err() != nil {
+		panic(signal.Reason())
+	}
+
+	if f.ctx.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		var e = newNamedError(runtime, "TimeoutError", fmt.Sprintf("The request timed out after %s", timeout))
+
+		_ = e.Set("code", errCodeTimedOut)
+
+		panic(e)
+	}
+
+	panic(newNetworkError(runtime, networkErrorCode(err), err))
 }
 
 type fetchResponse struct { // https://developer.mozilla.org/en-US/docs/Web/API/Response
 	runtime *js.Runtime
 
-	// Body contents
-	Body string `json:"body"`
+	raw         []byte // raw body bytes
+	text        string // decoded body (it is decoded on the first use)
+	decoded     bool   // the text is decoded
+	contentType string
+	header      http.Header
+	stream      *fetchBodyStream // nil if the body is not streamed
 
-	// The Headers object associated with the response
-	Headers map[string]string `json:"headers"`
+	// The Headers object associated with the response (header values can be also read as the properties)
+	Headers *js.Object `json:"headers"`
 
 	// A boolean indicating whether the response was successful (status in the range 200 – 299) or not
 	OK bool `json:"ok"`
 
 	// Indicates whether the response is the result of a redirect (that is, its URL list has more than one entry)
-	Redirected bool `json:"redirected"` // TODO: ❌ not implemented
+	Redirected bool `json:"redirected"`
+
+	// The followed redirects chain (each redirect response URL and status code), the last response is not included
+	// (so it is always empty for the "manual" and "error" redirect modes)
+	Redirects []fetchRedirect `json:"redirects"`
 
 	// The status code of the response (this will be 200 for a success)
 	Status int `json:"status"`
@@ -149,6 +641,54 @@ type fetchResponse struct { // https://developer.mozilla.org/en-US/docs/Web/API/
 
 	// The URL of the response
 	URL string `json:"url"`
+
+	// The negotiated TLS connection details (null for the plain HTTP)
+	TLS *fetchTLS `json:"tls"`
+
+	// The request timing breakdown (in milliseconds)
+	Timings fetchTimings `json:"timings"`
+
+	// The number of the sent attempts (more than one if the request was retried)
+	Attempts int `json:"attempts"`
+
+	// The negotiated protocol (http1, h2 or h2c)
+	Protocol string `json:"protocol"`
+
+	// The connection details (of the last request, when redirects are followed)
+	Connection fetchConnection `json:"connection"`
+}
+
+// fetchRedirect is a single redirect in the redirects chain.
+type fetchRedirect struct {
+	// The URL of the redirect response
+	URL string `json:"url"`
+
+	// The status code of the redirect response (e.g. 301 or 302)
+	Status int `json:"status"`
+}
+
+// object returns the JS response object.
+func (r *fetchResponse) object() *js.Object {
+	return r.runtime.NewDynamicObject(&fetchResponseObject{r: r, host: r.runtime.ToValue(r).ToObject(r.runtime)})
+}
+
+// rawBody returns the raw body bytes (the rest of the streamed body is read on the first call).
+func (r *fetchResponse) rawBody() []byte {
+	if r.stream != nil && r.raw == nil {
+		r.raw = r.stream.readAll()
+	}
+
+	return r.raw
+}
+
+// decodedText returns the body, decoded using the response charset (it is decoded on the first call, so the
+// binary bodies are not decoded unless the text is requested).
+func (r *fetchResponse) decodedText() string {
+	if !r.decoded {
+		r.text, r.decoded = decodeText(r.rawBody(), r.contentType), true
+	}
+
+	return r.text
 }
 
 func (r *fetchResponse) setStatusCode(code int) {
@@ -159,27 +699,49 @@ func (r *fetchResponse) setStatusCode(code int) {
 // ArrayBuffer returns body data as an ArrayBuffer.
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/arrayBuffer
 func (r *fetchResponse) ArrayBuffer(_ js.FunctionCall) js.Value {
-	return r.runtime.ToValue(r.runtime.NewArrayBuffer([]byte(r.Body)))
+	var raw = r.rawBody()
+
+	return r.runtime.ToValue(r.runtime.NewArrayBuffer(append(make([]byte, 0, len(raw)), raw...)))
 }
 
 // Blob returns a Blob representation of the response body.
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/blob
 func (r *fetchResponse) Blob(_ js.FunctionCall) js.Value { return js.Undefined() } // TODO: ❌ not implemented
 
-// Clone returns a clone of a fetchResponse object.
+// Clone returns a clone of a fetchResponse object. The streamed responses cannot be cloned.
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/clone
-func (r *fetchResponse) Clone(_ js.FunctionCall) js.Value { return js.Undefined() } // TODO: ❌ not implemented
+func (r *fetchResponse) Clone(_ js.FunctionCall) js.Value {
+	if r.stream != nil {
+		panic(r.runtime.NewTypeError("Cannot clone the streamed response"))
+	}
+
+	var clone = *r
+
+	clone.header = r.header.Clone()
+	clone.Headers = newHeaders(r.runtime, clone.header).object()
+	clone.Redirects = append(make([]fetchRedirect, 0, len(r.Redirects)), r.Redirects...)
 
-// FormData returns a FormData representation of the response body.
+	return clone.object()
+}
+
+// FormData returns a FormData representation of the response body (multipart/form-data or
+// application/x-www-form-urlencoded).
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/formData
-func (r *fetchResponse) FormData(_ js.FunctionCall) js.Value { return js.Undefined() } // TODO: ❌ not implemented
+func (r *fetchResponse) FormData(_ js.FunctionCall) js.Value {
+	form, err := parseForm(r.runtime, r.rawBody(), r.contentType)
+	if err != nil {
+		panic(r.runtime.NewTypeError("Cannot parse the body as form data: " + err.Error()))
+	}
+
+	return form.object()
+}
 
 // Json returns a result of parsing the response body text as JSON.
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/json
 func (r *fetchResponse) Json(_ js.FunctionCall) js.Value {
 	var value any
 
-	if err := json.Unmarshal([]byte(r.Body), &value); err == nil {
+	if err := json.Unmarshal([]byte(r.decodedText()), &value); err == nil {
 		return r.runtime.ToValue(value)
 	} else {
 		panic(r.runtime.ToValue("Wrong JSON: " + err.Error()))
@@ -189,5 +751,39 @@ func (r *fetchResponse) Json(_ js.FunctionCall) js.Value {
 // Text returns a text representation of the response body.
 // https://developer.mozilla.org/en-US/docs/Web/API/Response/text
 func (r *fetchResponse) Text(_ js.FunctionCall) js.Value {
-	return r.runtime.ToValue(r.Body)
+	return r.runtime.ToValue(r.decodedText())
+}
+
+// fetchResponseObject is the JS response object - the fetchResponse properties and methods, with the `body`
+// property, that is the decoded body text (decoded on the first access) or the stream for the streamed responses.
+type fetchResponseObject struct {
+	r      *fetchResponse
+	host   *js.Object // the fetchResponse host object
+	stream js.Value   // the streamed body object (created on the first access)
 }
+
+func (o *fetchResponseObject) Get(key string) js.Value {
+	if key != "body" {
+		return o.host.Get(key)
+	}
+
+	if o.r.stream == nil {
+		return o.r.runtime.ToValue(o.r.decodedText())
+	}
+
+	if o.stream == nil {
+		o.stream = o.r.runtime.ToValue(o.r.stream)
+	}
+
+	return o.stream
+}
+
+func (o *fetchResponseObject) Set(key string, val js.Value) bool {
+	return key != "body" && o.host.Set(key, val) == nil
+}
+
+func (o *fetchResponseObject) Has(key string) bool { return key == "body" || o.host.Get(key) != nil }
+
+func (o *fetchResponseObject) Delete(string) bool { return false }
+
+func (o *fetchResponseObject) Keys() []string { return append([]string{"body"}, o.host.Keys()...) }
@@ -0,0 +1,296 @@
+package addons
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // MD5 is required by the digest auth
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	js "github.com/dop251/goja"
+)
+
+// Authentication types (the `auth.type` option values).
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authDigest = "digest"
+	authOAuth2 = "oauth2"
+)
+
+// requestAuth is the parsed `auth` option of the request.
+type requestAuth struct {
+	kind               string
+	username, password string        // basic and digest
+	token              string        // bearer
+	oauth2             *OAuth2Client // oauth2
+	host               string        // the request host (digest auth is sent to it only)
+}
+
+// parseAuth parses the `auth` option. It can be the OAuth2 client (created using `http.oauth2()`) or the object
+// with the `type` property.
+func (f *Fetch) parseAuth(runtime *js.Runtime, v js.Value) *requestAuth {
+	if client, ok := v.Export().(*OAuth2Client); ok {
+		return &requestAuth{kind: authOAuth2, oauth2: client}
+	}
+
+	var (
+		obj  = v.ToObject(runtime)
+		auth = &requestAuth{kind: strings.ToLower(stringProperty(obj, "type"))}
+	)
+
+	switch auth.kind {
+	case authBasic, authDigest:
+		auth.username, auth.password = stringProperty(obj, "username"), stringProperty(obj, "password")
+
+	case authBearer:
+		if auth.token = stringProperty(obj, "token"); auth.token == "" {
+			panic(runtime.NewTypeError("The bearer token must not be empty"))
+		}
+
+	case authOAuth2:
+		auth.oauth2 = f.oauth2Client(runtime, obj)
+
+	case "":
+		panic(runtime.NewTypeError("The auth type is required (basic, bearer, digest or oauth2)"))
+
+	default:
+		panic(runtime.NewTypeError("Unsupported auth type: " + auth.kind))
+	}
+
+	return auth
+}
+
+// stringProperty returns the object property as a string (empty if it is not set).
+func stringProperty(obj *js.Object, name string) string {
+	if v := obj.Get(name); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+		return v.String()
+	}
+
+	return ""
+}
+
+// apply sets up the request headers (the digest auth is handled by the transport, after the server challenge).
+func (a *requestAuth) apply(headers http.Header) {
+	switch a.kind {
+	case authBasic:
+		var req = http.Request{Header: make(http.Header)}
+
+		req.SetBasicAuth(a.username, a.password)
+		headers.Set("Authorization", req.Header.Get("Authorization"))
+
+	case authBearer:
+		headers.Set("Authorization", "Bearer "+a.token)
+
+	case authOAuth2:
+		headers.Set("Authorization", "Bearer "+a.oauth2.Token())
+	}
+}
+
+// digestTransport handles the digest authentication challenge (RFC 7616): when the server responds with 401 and the
+// `WWW-Authenticate: Digest ...` header, the request is repeated with the computed credentials. Only the requests
+// to the original host are authenticated.
+type digestTransport struct {
+	next               http.RoundTripper
+	host               string
+	username, password string
+	nc                 uint32 // nonce count
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	var challenge map[string]string
+
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		if len(value) > 7 && strings.EqualFold(value[:7], "digest ") { //nolint:gomnd
+			challenge = parseAuthParams(value[7:])
+
+			break
+		}
+	}
+
+	if challenge == nil {
+		return resp, nil // not a digest challenge
+	}
+
+	authorization, err := t.authorization(req, challenge)
+	if err != nil {
+		return resp, nil //nolint:nilerr // the server response is returned as is for the unsupported challenges
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	var retry = req.Clone(req.Context())
+
+	retry.Header.Set("Authorization", authorization)
+
+	if body != nil {
+		retry.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return t.next.RoundTrip(retry)
+}
+
+// authorization computes the `Authorization` header value for the digest challenge.
+func (t *digestTransport) authorization(req *http.Request, challenge map[string]string) (string, error) {
+	var (
+		algorithm = challenge["algorithm"]
+		newHash   func() hash.Hash
+	)
+
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+
+	case "SHA-256":
+		newHash = sha256.New
+
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %s", algorithm)
+	}
+
+	var qop string
+
+	if challenge["qop"] != "" {
+		for _, q := range strings.Split(challenge["qop"], ",") {
+			if strings.TrimSpace(q) == "auth" {
+				qop = "auth"
+			}
+		}
+
+		if qop == "" {
+			return "", fmt.Errorf("unsupported digest qop %s", challenge["qop"])
+		}
+	}
+
+	var h = func(s string) string {
+		var hh = newHash()
+
+		_, _ = hh.Write([]byte(s))
+
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	var cnonceBytes = make([]byte, 16) //nolint:gomnd
+
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", err
+	}
+
+	t.nc++
+
+	var (
+		realm, nonce = challenge["realm"], challenge["nonce"]
+		uri          = req.URL.RequestURI()
+		cnonce       = hex.EncodeToString(cnonceBytes)
+		nc           = fmt.Sprintf("%08x", t.nc)
+		ha1          = h(t.username + ":" + realm + ":" + t.password)
+		ha2          = h(req.Method + ":" + uri)
+		response     string
+	)
+
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+
+	if qop != "" {
+		response = h(strings.Join([]string{ha1, nonce, nc, cnonce, qop, ha2}, ":"))
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		t.username, realm, nonce, uri, response,
+	)
+
+	if algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", algorithm)
+	}
+
+	if qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce="%s"`, qop, nc, cnonce)
+	}
+
+	if opaque, ok := challenge["opaque"]; ok {
+		fmt.Fprintf(&b, `, opaque="%s"`, opaque)
+	}
+
+	return b.String(), nil
+}
+
+// parseAuthParams parses the comma-separated `name=value` (or `name="quoted value"`) authentication parameters.
+func parseAuthParams(s string) map[string]string {
+	var params = make(map[string]string)
+
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, " ,") {
+		var eq = strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+
+		var name, value = strings.ToLower(strings.TrimSpace(s[:eq])), ""
+
+		s = strings.TrimSpace(s[eq+1:])
+
+		if strings.HasPrefix(s, `"`) {
+			var (
+				b    strings.Builder
+				rest string // the rest after the closing quote
+			)
+
+			for i := 1; i < len(s); i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				} else if s[i] == '"' {
+					rest = s[i+1:]
+
+					break
+				}
+
+				b.WriteByte(s[i])
+			}
+
+			value, s = b.String(), rest
+		} else {
+			var end = strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+
+			value, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+
+		params[name] = value
+	}
+
+	return params
+}
@@ -0,0 +1,90 @@
+package addons
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"unicode/utf8"
+
+	js "github.com/dop251/goja"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// requestBody converts the JS value to the request body and returns its content type (empty if it cannot be
+// detected). ArrayBuffer and its views (Uint8Array, DataView, etc.) are sent as is, FormData and URLSearchParams are
+// encoded, and any other value is converted to the string.
+func requestBody(runtime *js.Runtime, v js.Value) (io.Reader, string) {
+	switch form := v.Export().(type) {
+	case *FormData:
+		return form.encode()
+
+	case *URLSearchParams:
+		return form.encode()
+	}
+
+	if b, ok := binaryValue(runtime, v); ok {
+		return bytes.NewReader(b), ""
+	}
+
+	return bytes.NewBufferString(v.String()), ""
+}
+
+// binaryValue returns the bytes of the ArrayBuffer or its view (typed array or DataView).
+func binaryValue(runtime *js.Runtime, v js.Value) ([]byte, bool) {
+	if buf, ok := v.Export().(js.ArrayBuffer); ok {
+		return buf.Bytes(), true
+	}
+
+	obj, isObject := v.(*js.Object)
+	if !isObject {
+		return nil, false
+	}
+
+	var bufValue = obj.Get("buffer")
+	if bufValue == nil {
+		return nil, false
+	}
+
+	buf, ok := bufValue.Export().(js.ArrayBuffer)
+	if !ok {
+		return nil, false
+	}
+
+	var (
+		data   = buf.Bytes()
+		offset = obj.Get("byteOffset").ToInteger()
+		length = obj.Get("byteLength").ToInteger()
+	)
+
+	if offset < 0 || length < 0 || offset+length > int64(len(data)) {
+		panic(runtime.NewTypeError("Wrong ArrayBuffer view bounds"))
+	}
+
+	return data[offset : offset+length], true
+}
+
+// decodeText decodes the response body to the string, using the charset from the content type (UTF-8 is used by
+// default or when the charset is unknown).
+func decodeText(raw []byte, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if name, ok := params["charset"]; ok {
+			if enc, encErr := htmlindex.Get(name); encErr == nil {
+				if encName, _ := htmlindex.Name(enc); encName != "utf-8" {
+					if decoded, decErr := enc.NewDecoder().Bytes(raw); decErr == nil {
+						return string(decoded)
+					}
+				}
+			}
+		}
+	}
+
+	if bytes.HasPrefix(raw, []byte("\xEF\xBB\xBF")) { // strip the UTF-8 BOM
+		raw = raw[3:]
+	}
+
+	if !utf8.Valid(raw) {
+		return string(bytes.ToValidUTF8(raw, []byte(string(utf8.RuneError))))
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,265 @@
+package addons
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CassetteMode is the mode of the recorded HTTP interactions (cassettes) usage.
+type CassetteMode uint8
+
+const (
+	CassetteRecord       CassetteMode = iota + 1 // send the requests and record the responses
+	CassetteReplay                               // replay the recorded responses, send the unmatched requests
+	CassetteReplayStrict                         // replay the recorded responses, fail on the unmatched requests
+)
+
+// errNotRecorded is returned in the strict replay mode when there is no recorded response for the request.
+var errNotRecorded = errors.New("no recorded response")
+
+// WithFetchCassette enables the VCR-style mode: request/response pairs are stored in the directory (one file per
+// interaction, keyed by the method, URL and body hash) and replayed on subsequent runs. When the same request is sent
+// several times, each response is stored separately (the last recorded one is replayed for the extra requests).
+// The response body is recorded as it is read by the client, so the streamed or too large bodies are recorded
+// partially.
+func WithFetchCassette(dir string, mode CassetteMode) FetchOption {
+	return func(f *Fetch) {
+		var c = &cassette{dir: dir, mode: mode, counters: make(map[string]int)}
+
+		f.middlewares = append(f.middlewares, c.wrap)
+	}
+}
+
+// cassette records and replays the HTTP interactions.
+type cassette struct {
+	dir  string
+	mode CassetteMode
+
+	mu       sync.Mutex
+	counters map[string]int // the number of the sent requests per key
+}
+
+// interaction is the recorded request/response pair (the cassette file content).
+type interaction struct {
+	Request struct {
+		Method   string `json:"method"`
+		URL      string `json:"url"`
+		BodyHash string `json:"bodyHash"` // SHA-256 of the request body
+	} `json:"request"`
+
+	Response struct {
+		Status       int         `json:"status"`
+		Proto        string      `json:"proto"`
+		Headers      http.Header `json:"headers"`
+		Body         string      `json:"body"`
+		BodyEncoding string      `json:"bodyEncoding,omitempty"` // "base64" for the binary body
+	} `json:"response"`
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+func (c *cassette) wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var reqBody []byte
+
+		if req.Body != nil && req.Body != http.NoBody {
+			var err error
+
+			if reqBody, err = io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+
+			_ = req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		}
+
+		var (
+			bodyHash = sha256.Sum256(reqBody)
+			key      = sha256.Sum256([]byte(req.Method + " " + req.URL.String() + " " + hex.EncodeToString(bodyHash[:])))
+			name     = hex.EncodeToString(key[:16])
+		)
+
+		c.mu.Lock()
+		c.counters[name]++
+		var n = c.counters[name]
+		c.mu.Unlock()
+
+		if c.mode == CassetteRecord {
+			return c.record(next, req, c.fileName(name, n), hex.EncodeToString(bodyHash[:]))
+		}
+
+		for ; n > 0; n-- { // the last recorded response is used for the extra requests
+			if resp, err := c.replay(req, c.fileName(name, n)); err == nil {
+				return resp, nil
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+
+		if c.mode == CassetteReplayStrict {
+			return nil, fmt.Errorf("%w for %s %s (body hash %s) in %s",
+				errNotRecorded, req.Method, req.URL, hex.EncodeToString(bodyHash[:8]), c.dir,
+			)
+		}
+
+		return next.RoundTrip(req)
+	})
+}
+
+// fileName returns the cassette file path for the n-th request with the key.
+func (c *cassette) fileName(key string, n int) string {
+	if n > 1 {
+		key += "-" + strconv.Itoa(n)
+	}
+
+	return filepath.Join(c.dir, key+".json")
+}
+
+// record sends the request and returns the response with the body, that is stored into the file while it is read by
+// the client (so the streamed and limited bodies are recorded the same way as they are read).
+func (c *cassette) record(next http.RoundTripper, req *http.Request, path, bodyHash string) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err // network errors are not recorded
+	}
+
+	var i = new(interaction)
+
+	i.Request.Method, i.Request.URL, i.Request.BodyHash = req.Method, req.URL.String(), bodyHash
+	i.Response.Status, i.Response.Proto, i.Response.Headers = resp.StatusCode, resp.Proto, resp.Header.Clone()
+
+	resp.Body = &cassetteBody{ReadCloser: resp.Body, cassette: c, path: path, interaction: i}
+
+	return resp, nil
+}
+
+// cassetteBody is the response body, that is written into the cassette file when it is read to the end or closed.
+type cassetteBody struct {
+	io.ReadCloser
+	cassette    *cassette
+	path        string
+	interaction *interaction
+	buf         bytes.Buffer
+	once        sync.Once
+	err         error
+}
+
+func (b *cassetteBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.buf.Write(p[:n])
+
+	if errors.Is(err, io.EOF) {
+		if writeErr := b.write(); writeErr != nil {
+			return n, writeErr
+		}
+	}
+
+	return n, err
+}
+
+func (b *cassetteBody) Close() error {
+	var closeErr = b.ReadCloser.Close()
+
+	if err := b.write(); err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// write stores the read body into the cassette file (once).
+func (b *cassetteBody) write() error {
+	b.once.Do(func() { b.err = b.cassette.write(b.path, b.interaction, b.buf.Bytes()) })
+
+	return b.err
+}
+
+// write stores the interaction with the response body into the file.
+func (c *cassette) write(path string, i *interaction, body []byte) error {
+	if utf8.Valid(body) {
+		i.Response.Body = string(body)
+	} else {
+		i.Response.Body, i.Response.BodyEncoding = base64.StdEncoding.EncodeToString(body), "base64"
+	}
+
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(c.dir, 0o755); err != nil { //nolint:gomnd
+		return fmt.Errorf("cannot create the cassettes directory: %w", err)
+	}
+
+	if err = os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec,gomnd
+		return fmt.Errorf("cannot write the cassette: %w", err)
+	}
+
+	return nil
+}
+
+// replay reads the recorded response from the file.
+func (c *cassette) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var i interaction
+
+	if err = json.Unmarshal(data, &i); err != nil {
+		return nil, fmt.Errorf("wrong cassette %s: %w", path, err)
+	}
+
+	var body = []byte(i.Response.Body)
+
+	if i.Response.BodyEncoding == "base64" {
+		if body, err = base64.StdEncoding.DecodeString(i.Response.Body); err != nil {
+			return nil, fmt.Errorf("wrong cassette %s body: %w", path, err)
+		}
+	}
+
+	major, minor, ok := http.ParseHTTPVersion(i.Response.Proto)
+	if !ok {
+		major, minor, i.Response.Proto = 1, 1, "HTTP/1.1"
+	}
+
+	if i.Response.Headers == nil {
+		i.Response.Headers = make(http.Header)
+	}
+
+	var contentLength = int64(len(body))
+
+	// the body may be recorded partially (e.g. when it was too large), so the declared length is replayed as well
+	if declared, parseErr := strconv.ParseInt(i.Response.Headers.Get("Content-Length"), 10, 64); parseErr == nil {
+		contentLength = declared
+	}
+
+	return &http.Response{
+		Status:        strings.TrimSpace(strconv.Itoa(i.Response.Status) + " " + http.StatusText(i.Response.Status)),
+		StatusCode:    i.Response.Status,
+		Proto:         i.Response.Proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        i.Response.Headers,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: contentLength,
+		Request:       req,
+	}, nil
+}
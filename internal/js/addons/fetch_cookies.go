@@ -0,0 +1,214 @@
+package addons
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	js "github.com/dop251/goja"
+	"golang.org/x/net/publicsuffix"
+)
+
+// cookieJar is a cookie jar that can be cleared (entirely or per domain). The public suffix list is used, so the
+// cookies cannot be set for the public suffixes (e.g. `co.uk`).
+type cookieJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	records map[cookieRecord]*url.URL // the stored cookies (the jar cannot list them), with the URLs they were set for
+}
+
+// cookieRecord identifies the stored cookie.
+type cookieRecord struct {
+	host, domain, path, name string // domain is the cookie Domain attribute (empty for the host-only cookies)
+}
+
+var _ http.CookieJar = (*cookieJar)(nil) // verify that the cookieJar implements the http.CookieJar interface
+
+func newCookieJar() *cookieJar {
+	var j = &cookieJar{}
+
+	j.reset()
+
+	return j
+}
+
+func (j *cookieJar) reset() {
+	j.jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List}) // error is always nil
+	j.records = make(map[cookieRecord]*url.URL)
+}
+
+// SetCookies implements the http.CookieJar interface.
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+
+	for _, cookie := range cookies {
+		var (
+			record = cookieRecord{
+				host:   strings.ToLower(u.Hostname()),
+				domain: strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")),
+				path:   cookiePath(u, cookie),
+				name:   cookie.Name,
+			}
+			copied = *u
+		)
+
+		j.records[record] = &copied
+	}
+}
+
+// cookiePath returns the effective cookie path - the Path attribute, or the default path of the URL the cookie was set
+// for (https://www.rfc-editor.org/rfc/rfc6265#section-5.1.4), so the cookies with the same name, set without the
+// Path attribute for the different paths, are not mixed up.
+func cookiePath(u *url.URL, cookie *http.Cookie) string {
+	if strings.HasPrefix(cookie.Path, "/") {
+		return cookie.Path
+	}
+
+	var path = u.Path
+
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		return path[:i]
+	}
+
+	return "/" // the path is empty, does not start with the "/" or has the single "/"
+}
+
+// Cookies implements the http.CookieJar interface.
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.jar.Cookies(u)
+}
+
+// Clear removes the cookies of the domain (including its subdomains) by expiring them. The cookies of the parent
+// domains (e.g. `example.com` cookies for the `api.example.com`) are kept. All the cookies are removed if the domain
+// is empty.
+func (j *cookieJar) Clear(domain string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if domain == "" {
+		j.reset()
+
+		return
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	for record, u := range j.records {
+		var cookieDomain = record.domain
+
+		if cookieDomain == "" {
+			cookieDomain = record.host
+		}
+
+		if cookieDomain != domain && !strings.HasSuffix(cookieDomain, "."+domain) {
+			continue
+		}
+
+		j.jar.SetCookies(u, []*http.Cookie{{Name: record.name, Domain: record.domain, Path: record.path, MaxAge: -1}})
+
+		delete(j.records, record)
+	}
+}
+
+// Cookies is a JS API for the cookie jar, used by the fetch requests.
+type Cookies struct {
+	runtime *js.Runtime
+	jar     *cookieJar
+}
+
+// toURL converts the JS value (URL or domain name) to the URL.
+func (c *Cookies) toURL(v js.Value) *url.URL {
+	var s = v.String()
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		panic(c.runtime.NewTypeError("Invalid URL or domain: " + v.String()))
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u
+}
+
+// Get returns the cookies that will be sent with the request to the URL (or domain).
+func (c *Cookies) Get(args ...js.Value) js.Value {
+	if len(args) == 0 {
+		panic(c.runtime.NewTypeError("URL or domain is required"))
+	}
+
+	var (
+		cookies = c.jar.Cookies(c.toURL(args[0]))
+		result  = make([]any, 0, len(cookies))
+	)
+
+	for _, cookie := range cookies {
+		result = append(result, map[string]any{"name": cookie.Name, "value": cookie.Value})
+	}
+
+	return c.runtime.ToValue(result)
+}
+
+// Set sets the cookie for the URL (or domain).
+func (c *Cookies) Set(args ...js.Value) {
+	if len(args) < 3 { //nolint:gomnd
+		panic(c.runtime.NewTypeError("URL (or domain), cookie name and value are required"))
+	}
+
+	var cookie = &http.Cookie{Name: args[1].String(), Value: args[2].String()}
+
+	if len(args) > 3 && !js.IsUndefined(args[3]) && !js.IsNull(args[3]) { //nolint:gomnd
+		var options = args[3].ToObject(c.runtime)
+
+		if v := options.Get("path"); v != nil {
+			cookie.Path = v.String()
+		}
+
+		if v := options.Get("domain"); v != nil {
+			cookie.Domain = v.String()
+		}
+
+		if v := options.Get("maxAge"); v != nil {
+			cookie.MaxAge = int(v.ToInteger())
+		}
+
+		if v := options.Get("expires"); v != nil {
+			cookie.Expires = time.UnixMilli(v.ToInteger()) // Date objects are converted to milliseconds
+		}
+
+		if v := options.Get("secure"); v != nil {
+			cookie.Secure = v.ToBoolean()
+		}
+
+		if v := options.Get("httpOnly"); v != nil {
+			cookie.HttpOnly = v.ToBoolean()
+		}
+	}
+
+	c.jar.SetCookies(c.toURL(args[0]), []*http.Cookie{cookie})
+}
+
+// Clear removes the cookies of the domain (or URL). All the cookies are removed if the domain is not passed.
+func (c *Cookies) Clear(args ...js.Value) {
+	if len(args) == 0 || js.IsUndefined(args[0]) || js.IsNull(args[0]) {
+		c.jar.Clear("")
+
+		return
+	}
+
+	c.jar.Clear(c.toURL(args[0]).Hostname())
+}
@@ -0,0 +1,163 @@
+package addons
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tarampampam/poke/internal/log"
+	"github.com/tarampampam/poke/internal/redact"
+)
+
+// WithFetchLogger sets up the logger for the HTTP debug logging.
+func WithFetchLogger(l log.Logger) FetchOption {
+	return func(f *Fetch) { f.log = l }
+}
+
+// WithFetchDebug enables the debug logging (at the debug level) of all the requests and responses. It can be
+// overridden per request using the `debug` option.
+func WithFetchDebug(enabled bool) FetchOption {
+	return func(f *Fetch) { f.debug = enabled }
+}
+
+const debugMaxBodySize = 4096 // bodies are truncated to this size (in bytes) in the debug logs
+
+// debugTransport logs the requests and responses (including the redirects) sent through the transport.
+type debugTransport struct {
+	next http.RoundTripper
+	log  log.Logger
+}
+
+func (t debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	t.log.Debug(
+		"HTTP request"+debugHeaders("> ", req.Header)+formatDebugBody(reqBody, len(reqBody)),
+		log.With("method", req.Method),
+		log.With("url", req.URL.String()),
+	)
+
+	var startedAt = time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.log.Debug("HTTP request failed", log.With("url", req.URL.String()), log.With("error", err))
+
+		return nil, err
+	}
+
+	resp.Body = &debugBodyReader{ReadCloser: resp.Body, done: func(body []byte, size int) {
+		t.log.Debug(
+			"HTTP response"+debugHeaders("< ", resp.Header)+formatDebugBody(body, size),
+			log.With("status", resp.StatusCode),
+			log.With("url", req.URL.String()),
+			log.With("duration", time.Since(startedAt).Round(time.Microsecond)),
+		)
+	}}
+
+	return resp, nil
+}
+
+// debugHeaders formats the headers for the debug log (sensitive values are redacted).
+func debugHeaders(prefix string, h http.Header) string {
+	var (
+		b     strings.Builder
+		names = make([]string, 0, len(h))
+	)
+
+	for name := range h {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range h[name] {
+			b.WriteString("\n" + prefix + name + ": " + redact.HeaderValue(name, value))
+		}
+	}
+
+	return b.String()
+}
+
+// formatDebugBody formats the body (of the given full size) for the debug log.
+func formatDebugBody(body []byte, size int) string {
+	if size == 0 {
+		return ""
+	}
+
+	var truncated = len(body) > debugMaxBodySize || len(body) < size
+
+	if len(body) > debugMaxBodySize {
+		body = body[:debugMaxBodySize]
+	}
+
+	if truncated { // the last character may be cut
+		for i := 0; i < utf8.UTFMax-1 && len(body) > 0 && !utf8.Valid(body); i++ {
+			body = body[:len(body)-1]
+		}
+	}
+
+	switch {
+	case !utf8.Valid(body):
+		return fmt.Sprintf("\n[binary body, %d bytes]", size)
+
+	case truncated:
+		return fmt.Sprintf("\n%s\n[truncated, %d bytes total]", body, size)
+	}
+
+	return "\n" + string(body)
+}
+
+// debugBodyReader reads the response body and calls the done function with its beginning (up to the limit) and the
+// full size, when the body is read to the end or closed.
+type debugBodyReader struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	size int
+	once sync.Once
+	done func(body []byte, size int)
+}
+
+func (b *debugBodyReader) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.size += n
+
+	if rest := debugMaxBodySize - b.buf.Len(); rest > 0 {
+		if n < rest {
+			rest = n
+		}
+
+		b.buf.Write(p[:rest])
+	}
+
+	if err != nil {
+		b.once.Do(func() { b.done(b.buf.Bytes(), b.size) })
+	}
+
+	return n, err
+}
+
+func (b *debugBodyReader) Close() error {
+	b.once.Do(func() { b.done(b.buf.Bytes(), b.size) })
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,171 @@
+package addons
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	js "github.com/dop251/goja"
+)
+
+// FetchDefaults are the default options for all the HTTP requests.
+type FetchDefaults struct {
+	BaseURL string      // relative request URLs are resolved against it
+	Headers http.Header // merged with the request headers (request headers take precedence)
+	Query   url.Values  // merged with the request URL query and the `query` option
+}
+
+// WithFetchDefaults sets up the default options for the requests.
+func WithFetchDefaults(d FetchDefaults) FetchOption {
+	return func(f *Fetch) {
+		f.defaults.BaseURL = d.BaseURL
+
+		for name, values := range d.Headers {
+			f.defaults.Headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+
+		for name, values := range d.Query {
+			f.defaults.Query[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+// defaultsHandler is the `http.defaults({baseURL, headers, query})` function. The base URL is replaced, headers and
+// query parameters are merged (a null value removes the default). Current defaults are returned.
+func (f *Fetch) defaultsHandler(runtime *js.Runtime) func(call js.FunctionCall) js.Value {
+	return func(call js.FunctionCall) js.Value {
+		if arg := call.Argument(0); !js.IsUndefined(arg) && !js.IsNull(arg) {
+			var options = arg.ToObject(runtime)
+
+			if v := options.Get("baseURL"); v != nil && !js.IsUndefined(v) {
+				if js.IsNull(v) {
+					f.defaults.BaseURL = ""
+				} else {
+					f.defaults.BaseURL = v.String()
+				}
+			}
+
+			if v := options.Get("headers"); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+				var headers = v.ToObject(runtime)
+
+				for _, name := range headers.Keys() {
+					if value := headers.Get(name); js.IsNull(value) || js.IsUndefined(value) {
+						f.defaults.Headers.Del(name)
+					} else {
+						f.defaults.Headers.Set(name, value.String())
+					}
+				}
+			}
+
+			if v := options.Get("query"); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+				var query = v.ToObject(runtime)
+
+				for _, name := range query.Keys() {
+					if value := query.Get(name); js.IsNull(value) || js.IsUndefined(value) {
+						f.defaults.Query.Del(name)
+					} else {
+						f.defaults.Query[name] = queryValues(runtime, value)
+					}
+				}
+			}
+		}
+
+		var headers, query = make(map[string]string), make(map[string]any)
+
+		for name := range f.defaults.Headers {
+			headers[name] = f.defaults.Headers.Get(name)
+		}
+
+		for name, values := range f.defaults.Query {
+			if len(values) == 1 {
+				query[name] = values[0]
+			} else {
+				query[name] = values
+			}
+		}
+
+		return runtime.ToValue(map[string]any{"baseURL": f.defaults.BaseURL, "headers": headers, "query": query})
+	}
+}
+
+// queryValues converts the JS value (a scalar or an array) to the query parameter values.
+func queryValues(runtime *js.Runtime, v js.Value) []string {
+	if obj, isObject := v.(*js.Object); isObject && obj.ClassName() == "Array" {
+		var items []js.Value
+
+		_ = runtime.ExportTo(v, &items)
+
+		var values = make([]string, 0, len(items))
+
+		for _, item := range items {
+			values = append(values, item.String())
+		}
+
+		return values
+	}
+
+	return []string{v.String()}
+}
+
+// resolveURL resolves the request URL against the base URL (relative path is appended to the base URL path, like
+// the most HTTP clients do) and merges the query parameters: the request ones take precedence over the URL query,
+// and the URL query takes precedence over the defaults. The URL query is kept as is (the new parameters are
+// appended to it), only the parameters overridden by the request ones are removed.
+func resolveURL(rawURL, baseURL string, defaultQuery, query url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if baseURL != "" && !u.IsAbs() {
+		if u, err = url.Parse(strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(rawURL, "/")); err != nil {
+			return "", err
+		}
+	}
+
+	if len(defaultQuery) == 0 && len(query) == 0 {
+		return u.String(), nil
+	}
+
+	var (
+		inURL  = make(map[string]struct{})
+		pairs  []string
+		values = make(url.Values, len(defaultQuery)+len(query))
+	)
+
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		var name, _, _ = strings.Cut(pair, "=")
+
+		if unescaped, unescapeErr := url.QueryUnescape(name); unescapeErr == nil {
+			name = unescaped
+		}
+
+		if _, overridden := query[name]; overridden {
+			continue
+		}
+
+		inURL[name], pairs = struct{}{}, append(pairs, pair)
+	}
+
+	for name, v := range defaultQuery {
+		if _, exists := inURL[name]; !exists {
+			values[name] = v
+		}
+	}
+
+	for name, v := range query {
+		values[name] = v
+	}
+
+	if encoded := values.Encode(); encoded != "" {
+		pairs = append(pairs, encoded)
+	}
+
+	u.RawQuery = strings.Join(pairs, "&")
+
+	return u.String(), nil
+}
@@ -0,0 +1,89 @@
+package addons
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	js "github.com/dop251/goja"
+)
+
+// Network error codes, exposed as the `code` property of the thrown error.
+const (
+	errCodeConnRefused = "ECONNREFUSED" // the connection was refused by the server
+	errCodeConnReset   = "ECONNRESET"   // the connection was reset by the server
+	errCodeNotFound    = "ENOTFOUND"    // the host name cannot be resolved
+	errCodeTimedOut    = "ETIMEDOUT"    // the connection (or request) timed out
+	errCodeNetUnreach  = "ENETUNREACH"  // the network is unreachable
+	errCodeCanceled    = "ECANCELED"    // the request was canceled (e.g. the script execution is interrupted)
+	errCodeTLS         = "ETLS"         // TLS handshake or certificate verification failure
+	errCodeRedirect    = "EREDIRECT"    // the redirect is not allowed (or redirects limit is exceeded)
+	errCodeInvalidURL  = "EINVALIDURL"  // the request URL (or method) is invalid
+	errCodeNotRecorded = "ENOTRECORDED" // there is no recorded response for the request (strict replay mode)
+	errCodeTooLarge    = "ETOOLARGE"    // the response body exceeds the `maxBodySize` limit
+	errCodeUnknown     = "EUNKNOWN"     // any other network error
+)
+
+// errRedirect is returned by the HTTP client when the redirect is not allowed.
+var errRedirect = errors.New("redirect is not allowed")
+
+// networkErrorCode returns the error code for the transport (non-HTTP) error.
+func networkErrorCode(err error) string {
+	var (
+		dnsErr     *net.DNSError
+		netErr     net.Error
+		unknownCA  x509.UnknownAuthorityError
+		invalidErr x509.CertificateInvalidError
+		hostErr    x509.HostnameError
+		recordErr  tls.RecordHeaderError
+	)
+
+	switch {
+	case errors.Is(err, errRedirect):
+		return errCodeRedirect
+
+	case errors.Is(err, errNotRecorded):
+		return errCodeNotRecorded
+
+	case errors.Is(err, errBodyTooLarge):
+		return errCodeTooLarge
+
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errCodeConnRefused
+
+	case errors.Is(err, syscall.ECONNRESET):
+		return errCodeConnReset
+
+	case errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.EHOSTUNREACH):
+		return errCodeNetUnreach
+
+	case errors.As(err, &dnsErr) && !dnsErr.IsTimeout:
+		return errCodeNotFound
+
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errCodeTimedOut
+
+	case errors.Is(err, context.Canceled):
+		return errCodeCanceled
+
+	case errors.As(err, &unknownCA), errors.As(err, &invalidErr), errors.As(err, &hostErr),
+		errors.As(err, &recordErr), strings.Contains(err.Error(), "tls: "):
+		return errCodeTLS
+	}
+
+	return errCodeUnknown
+}
+
+// newNetworkError creates the JS TypeError (like the browser fetch does) with the error code and the cause.
+func newNetworkError(runtime *js.Runtime, code string, err error) *js.Object {
+	var e = runtime.NewTypeError("fetch failed (%s): %s", code, err.Error())
+
+	_ = e.Set("code", code)
+	_ = e.Set("cause", err.Error())
+
+	return e
+}
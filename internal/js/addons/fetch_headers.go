@@ -0,0 +1,271 @@
+package addons
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	js "github.com/dop251/goja"
+)
+
+// Headers is a WHATWG-style headers object (https://developer.mozilla.org/en-US/docs/Web/API/Headers). It is exposed
+// to JS as a dynamic object: besides the methods, header values (joined with ", ") can be read as the properties
+// (case-insensitively), so it can be used as a plain object for backward compatibility.
+type Headers struct {
+	runtime *js.Runtime
+	h       http.Header
+}
+
+var _ js.DynamicObject = (*Headers)(nil) // verify that the Headers implements the js.DynamicObject interface
+
+func newHeaders(runtime *js.Runtime, h http.Header) *Headers {
+	if h == nil {
+		h = make(http.Header)
+	}
+
+	return &Headers{runtime: runtime, h: h}
+}
+
+// headersConstructor is the Headers constructor. It accepts another Headers object, the plain object (values can be
+// arrays) or the array of pairs.
+func headersConstructor(runtime *js.Runtime) func(call js.ConstructorCall) *js.Object {
+	return func(call js.ConstructorCall) *js.Object {
+		var h = make(http.Header)
+
+		if init := call.Argument(0); !js.IsUndefined(init) && !js.IsNull(init) {
+			if pairs, isArray := init.Export().([]any); isArray {
+				for _, pair := range pairs {
+					p, ok := pair.([]any)
+					if !ok || len(p) != 2 { //nolint:gomnd
+						panic(runtime.NewTypeError("Each header pair must be an array with exactly two elements"))
+					}
+
+					h.Add(fmt.Sprint(p[0]), fmt.Sprint(p[1]))
+				}
+			} else {
+				h = headersFromValue(runtime, init)
+			}
+		}
+
+		return newHeaders(runtime, h).object()
+	}
+}
+
+// headersFromValue converts the Headers object or the plain object (values can be arrays, null and undefined values
+// are skipped) to the HTTP headers.
+func headersFromValue(runtime *js.Runtime, v js.Value) http.Header {
+	var h = make(http.Header)
+
+	if headers, ok := v.Export().(*Headers); ok {
+		return headers.h.Clone()
+	}
+
+	var obj = v.ToObject(runtime)
+
+	for _, name := range obj.Keys() {
+		var value = obj.Get(name)
+
+		if value == nil || js.IsUndefined(value) || js.IsNull(value) {
+			continue
+		}
+
+		for _, item := range queryValues(runtime, value) {
+			h.Add(name, item)
+		}
+	}
+
+	return h
+}
+
+// Header returns the underlying HTTP headers.
+func (h *Headers) Header() http.Header { return h.h }
+
+// object returns the JS object for the headers. Dynamic objects cannot have own symbol properties, so the
+// Symbol.iterator (for the `for...of` loops) is set on the prototype.
+func (h *Headers) object() *js.Object {
+	var (
+		obj   = h.runtime.NewDynamicObject(h)
+		proto = h.runtime.NewObject()
+	)
+
+	_ = proto.SetSymbol(js.SymIterator, func(js.FunctionCall) js.Value { return h.iterator(headerEntryPair(h.runtime)) })
+	_ = obj.SetPrototype(proto)
+
+	return obj
+}
+
+// sortedNames returns the lowercased header names in the sorted order (as the WHATWG spec requires).
+func (h *Headers) sortedNames() []string {
+	var names = make([]string, 0, len(h.h))
+
+	for name := range h.h {
+		names = append(names, strings.ToLower(name))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (h *Headers) get(name string) js.Value {
+	if values := h.h.Values(name); len(values) > 0 {
+		return h.runtime.ToValue(strings.Join(values, ", "))
+	}
+
+	return js.Null()
+}
+
+func (h *Headers) getAll(name string) js.Value {
+	var values = make([]any, 0)
+
+	for _, v := range h.h.Values(name) {
+		values = append(values, v)
+	}
+
+	return h.runtime.NewArray(values...)
+}
+
+// headerEntry is a single header entry (the lowercased name with the value).
+type headerEntry struct{ name, value string }
+
+// entries returns the header entries, sorted by name. Values of the same header are joined with ", ", except the
+// Set-Cookie ones, that are returned as the separate entries.
+func (h *Headers) entries() []headerEntry {
+	var entries = make([]headerEntry, 0, len(h.h))
+
+	for _, name := range h.sortedNames() {
+		if values := h.h.Values(name); name == "set-cookie" {
+			for _, value := range values {
+				entries = append(entries, headerEntry{name: name, value: value})
+			}
+		} else {
+			entries = append(entries, headerEntry{name: name, value: strings.Join(values, ", ")})
+		}
+	}
+
+	return entries
+}
+
+// iterator returns the iterator over the header entries (the entries are taken when the iteration starts).
+func (h *Headers) iterator(value func(headerEntry) js.Value) js.Value {
+	var entries = h.entries()
+
+	return newIterator(h.runtime, func() (js.Value, bool) {
+		if len(entries) == 0 {
+			return nil, false
+		}
+
+		var entry = entries[0]
+
+		entries = entries[1:]
+
+		return value(entry), true
+	})
+}
+
+// headerEntryPair returns the function, that converts the header entry to the [name, value] array.
+func headerEntryPair(runtime *js.Runtime) func(headerEntry) js.Value {
+	return func(entry headerEntry) js.Value { return runtime.NewArray(entry.name, entry.value) }
+}
+
+// method returns the Headers method by its name (nil if there is no such method).
+func (h *Headers) method(name string) any { //nolint:funlen
+	switch name {
+	case "append":
+		return func(name, value string) { h.h.Add(name, value) }
+
+	case "delete":
+		return func(name string) { h.h.Del(name) }
+
+	case "get":
+		return h.get
+
+	case "getAll":
+		return h.getAll
+
+	case "getSetCookie":
+		return func() js.Value { return h.getAll("Set-Cookie") }
+
+	case "has":
+		return func(name string) bool { return len(h.h.Values(name)) > 0 }
+
+	case "set":
+		return func(name, value string) { h.h.Set(name, value) }
+
+	case "entries":
+		return func() js.Value { return h.iterator(headerEntryPair(h.runtime)) }
+
+	case "keys":
+		return func() js.Value {
+			return h.iterator(func(entry headerEntry) js.Value { return h.runtime.ToValue(entry.name) })
+		}
+
+	case "values":
+		return func() js.Value {
+			return h.iterator(func(entry headerEntry) js.Value { return h.runtime.ToValue(entry.value) })
+		}
+
+	case "forEach":
+		return func(fn js.Callable) {
+			for _, entry := range h.entries() {
+				if _, err := fn(js.Undefined(), h.runtime.ToValue(entry.value), h.runtime.ToValue(entry.name)); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get implements the js.DynamicObject interface.
+func (h *Headers) Get(key string) js.Value {
+	if m := h.method(key); m != nil {
+		return h.runtime.ToValue(m)
+	}
+
+	if values := h.h.Values(key); len(values) > 0 {
+		return h.runtime.ToValue(strings.Join(values, ", "))
+	}
+
+	return nil
+}
+
+// Set implements the js.DynamicObject interface.
+func (h *Headers) Set(key string, val js.Value) bool {
+	if h.method(key) != nil {
+		return false
+	}
+
+	h.h.Set(key, val.String())
+
+	return true
+}
+
+// Has implements the js.DynamicObject interface.
+func (h *Headers) Has(key string) bool { return h.method(key) != nil || len(h.h.Values(key)) > 0 }
+
+// Delete implements the js.DynamicObject interface.
+func (h *Headers) Delete(key string) bool {
+	if h.method(key) != nil {
+		return false
+	}
+
+	h.h.Del(key)
+
+	return true
+}
+
+// Keys implements the js.DynamicObject interface (canonical header names, without methods).
+func (h *Headers) Keys() []string {
+	var keys = make([]string, 0, len(h.h))
+
+	for name := range h.h {
+		keys = append(keys, name)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
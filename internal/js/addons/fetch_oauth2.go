@@ -0,0 +1,257 @@
+package addons
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// OAuth2 grant types.
+const (
+	grantClientCredentials = "client_credentials"
+	grantPassword          = "password"
+	grantRefreshToken      = "refresh_token"
+)
+
+// oauth2TokenLeeway is the time before the token expiration, when the token is considered as expired (half of the
+// token lifetime is used for the short-living tokens).
+const oauth2TokenLeeway = 10 * time.Second
+
+// oauth2Config is the OAuth2 client configuration.
+type oauth2Config struct {
+	TokenURL     string
+	GrantType    string // client_credentials (default) or password
+	ClientID     string
+	ClientSecret string
+	Scope        string // space-separated scopes
+	Username     string // for the password grant
+	Password     string // for the password grant
+	AuthStyle    string // how the client credentials are sent: "body" (default) or "header" (basic auth)
+}
+
+// OAuth2Client fetches the OAuth2 access tokens (client credentials or password grant) and caches them until the
+// expiration. Expired tokens are refreshed using the refresh token (if the server provides it).
+type OAuth2Client struct {
+	f       *Fetch
+	runtime *js.Runtime
+	cfg     oauth2Config
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	expiresAt    time.Time // zero means that the token never expires
+}
+
+// oauth2Client returns the OAuth2 client for the configuration object. Clients are cached per runtime, so the same
+// configuration shares the tokens.
+func (f *Fetch) oauth2Client(runtime *js.Runtime, obj *js.Object) *OAuth2Client {
+	var cfg = oauth2Config{
+		TokenURL:     stringProperty(obj, "tokenURL"),
+		GrantType:    stringProperty(obj, "grantType"),
+		ClientID:     stringProperty(obj, "clientId"),
+		ClientSecret: stringProperty(obj, "clientSecret"),
+		Username:     stringProperty(obj, "username"),
+		Password:     stringProperty(obj, "password"),
+		AuthStyle:    stringProperty(obj, "authStyle"),
+	}
+
+	if scope := obj.Get("scope"); scope != nil && !js.IsUndefined(scope) && !js.IsNull(scope) {
+		cfg.Scope = strings.Join(queryValues(runtime, scope), " ")
+	}
+
+	if cfg.TokenURL == "" {
+		panic(runtime.NewTypeError("The OAuth2 token URL (tokenURL) is required"))
+	}
+
+	if cfg.GrantType == "" {
+		cfg.GrantType = grantClientCredentials
+	}
+
+	if cfg.GrantType != grantClientCredentials && cfg.GrantType != grantPassword {
+		panic(runtime.NewTypeError("Unsupported OAuth2 grant type: " + cfg.GrantType))
+	}
+
+	switch cfg.AuthStyle {
+	case "":
+		cfg.AuthStyle = "body"
+	case "body", "header":
+	default:
+		panic(runtime.NewTypeError("Unsupported OAuth2 auth style: " + cfg.AuthStyle))
+	}
+
+	f.oauth2Mu.Lock()
+	defer f.oauth2Mu.Unlock()
+
+	if client, ok := f.oauth2Clients[cfg]; ok {
+		return client
+	}
+
+	var client = &OAuth2Client{f: f, runtime: runtime, cfg: cfg}
+
+	f.oauth2Clients[cfg] = client
+
+	return client
+}
+
+// oauth2Handler is the `http.oauth2({tokenURL, clientId, ...})` function, that returns the OAuth2 client.
+func (f *Fetch) oauth2Handler(runtime *js.Runtime) func(call js.FunctionCall) js.Value {
+	return func(call js.FunctionCall) js.Value {
+		if arg := call.Argument(0); js.IsUndefined(arg) || js.IsNull(arg) {
+			panic(runtime.NewTypeError("The OAuth2 client options are required"))
+		}
+
+		return runtime.ToValue(f.oauth2Client(runtime, call.Argument(0).ToObject(runtime)))
+	}
+}
+
+// Token returns the access token (cached until the expiration).
+func (c *OAuth2Client) Token() string {
+	token, err := c.accessToken()
+	if err != nil {
+		panic(c.runtime.NewTypeError("Cannot get the OAuth2 access token: " + err.Error()))
+	}
+
+	return token
+}
+
+// Invalidate drops the cached tokens, so the next request gets the new one.
+func (c *OAuth2Client) Invalidate() {
+	c.mu.Lock()
+	c.token, c.refreshToken, c.expiresAt = "", "", time.Time{}
+	c.mu.Unlock()
+}
+
+// accessToken returns the cached access token, or requests the new one if it is missing or expired.
+func (c *OAuth2Client) accessToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expiresAt.IsZero() || time.Now().Before(c.expiresAt)) {
+		return c.token, nil
+	}
+
+	if c.refreshToken != "" {
+		if err := c.requestToken(url.Values{
+			"grant_type":    {grantRefreshToken},
+			"refresh_token": {c.refreshToken},
+		}); err == nil {
+			return c.token, nil
+		}
+
+		c.refreshToken = "" // the refresh token is not valid anymore, so the new token is requested
+	}
+
+	var form = url.Values{"grant_type": {c.cfg.GrantType}}
+
+	if c.cfg.Scope != "" {
+		form.Set("scope", c.cfg.Scope)
+	}
+
+	if c.cfg.GrantType == grantPassword {
+		form.Set("username", c.cfg.Username)
+		form.Set("password", c.cfg.Password)
+	}
+
+	if err := c.requestToken(form); err != nil {
+		return "", err
+	}
+
+	return c.token, nil
+}
+
+// requestToken sends the token request and stores the received token. It must be called with the lock held.
+func (c *OAuth2Client) requestToken(form url.Values) error {
+	if c.cfg.AuthStyle == "body" {
+		form.Set("client_id", c.cfg.ClientID)
+
+		if c.cfg.ClientSecret != "" {
+			form.Set("client_secret", c.cfg.ClientSecret)
+		}
+	}
+
+	var ctx, cancel = c.f.requestContext(c.f.timeout, nil)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if c.cfg.AuthStyle == "header" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	var client = c.f.client(requestOptions{redirect: redirectFollow, maxRedirects: 10, debug: c.f.debug}, //nolint:gomnd
+		&[]fetchRedirect{},
+	)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		AccessToken      string          `json:"access_token"`
+		RefreshToken     string          `json:"refresh_token"`
+		ExpiresIn        json.RawMessage `json:"expires_in"` // some servers send it as a string
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+
+	var parsingErr = json.Unmarshal(body, &payload)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if payload.Error != "" {
+			return fmt.Errorf("token endpoint responded with %d: %s",
+				resp.StatusCode, strings.TrimSpace(payload.Error+" "+payload.ErrorDescription),
+			)
+		}
+
+		return fmt.Errorf("token endpoint responded with %d", resp.StatusCode)
+	}
+
+	if parsingErr != nil {
+		return fmt.Errorf("wrong token response: %w", parsingErr)
+	}
+
+	if payload.AccessToken == "" {
+		return errors.New("token response has no access_token")
+	}
+
+	c.token, c.expiresAt = payload.AccessToken, time.Time{}
+
+	if payload.RefreshToken != "" {
+		c.refreshToken = payload.RefreshToken
+	}
+
+	if expiresIn, _ := strconv.ParseFloat(strings.Trim(string(payload.ExpiresIn), `"`), 64); expiresIn > 0 {
+		var ttl, leeway = time.Duration(expiresIn * float64(time.Second)), oauth2TokenLeeway
+
+		if leeway > ttl/2 { // for the short-living tokens
+			leeway = ttl / 2 //nolint:gomnd
+		}
+
+		c.expiresAt = time.Now().Add(ttl - leeway)
+	}
+
+	return nil
+}
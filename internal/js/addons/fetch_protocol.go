@@ -0,0 +1,215 @@
+package addons
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// HTTP protocols (the `protocol` option and the `res.protocol` values).
+const (
+	protocolHTTP1 = "http1" // HTTP/1.1
+	protocolH2    = "h2"    // HTTP/2 over TLS
+	protocolH2C   = "h2c"   // HTTP/2 over the plain TCP (prior knowledge)
+)
+
+// protocolTransport creates the transport that forces the protocol. The dialer and the proxy of the base transport
+// are used, so the Unix socket and proxy options are supported (HTTP/2 connections are tunneled through the proxy
+// using the CONNECT method). HTTP/3 is not supported, since it requires the QUIC transport.
+func protocolTransport(base *http.Transport, protocol string) http.RoundTripper {
+	var dial = base.DialContext
+
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	switch protocol {
+	case protocolH2:
+		dial = proxyDial(base.Proxy, "https", dial)
+	case protocolH2C:
+		dial = proxyDial(base.Proxy, "http", dial)
+	}
+
+	var tlsConfig = &tls.Config{} //nolint:gosec // the minimal version is set by the user
+
+	if base.TLSClientConfig != nil {
+		tlsConfig = base.TLSClientConfig.Clone()
+	}
+
+	switch protocol {
+	case protocolH2:
+		tlsConfig.NextProtos = []string{http2.NextProtoTLS}
+
+		return &http2.Transport{
+			TLSClientConfig: tlsConfig,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				conn, err := dial(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+
+				if cfg.ServerName == "" {
+					cfg = cfg.Clone()
+					cfg.ServerName, _, _ = net.SplitHostPort(addr)
+				}
+
+				var tlsConn = tls.Client(conn, cfg)
+
+				if err = tlsConn.HandshakeContext(ctx); err != nil {
+					_ = conn.Close()
+
+					return nil, err
+				}
+
+				return tlsConn, nil
+			},
+		}
+
+	case protocolH2C:
+		return &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr) // no TLS for the prior knowledge HTTP/2
+			},
+		}
+	}
+
+	// HTTP/1.1 only
+	tlsConfig.NextProtos = []string{"http/1.1"}
+
+	base.TLSClientConfig = tlsConfig
+	base.ForceAttemptHTTP2 = false
+	base.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper) // disables HTTP/2
+
+	return base
+}
+
+type dialFunc = func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// proxyDial wraps the dial function, so the connections are tunneled through the proxy (if it is set for the address)
+// using the CONNECT method. The scheme is used for the proxy selection (e.g. HTTPS_PROXY or HTTP_PROXY).
+func proxyDial(proxy func(*http.Request) (*url.URL, error), scheme string, dial dialFunc) dialFunc {
+	if proxy == nil {
+		return dial
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		proxyURL, err := proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+		if err != nil {
+			return nil, err
+		}
+
+		if proxyURL == nil {
+			return dial(ctx, network, addr)
+		}
+
+		return connectTunnel(ctx, dial, network, proxyURL, addr)
+	}
+}
+
+// connectTunnel connects to the HTTP(S) proxy and establishes the tunnel to the address using the CONNECT method.
+func connectTunnel(
+	ctx context.Context,
+	dial dialFunc,
+	network string,
+	proxyURL *url.URL,
+	addr string,
+) (net.Conn, error) {
+	var proxyAddr, port = proxyURL.Host, "80"
+
+	switch proxyURL.Scheme {
+	case "http":
+	case "https":
+		port = "443"
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %s (only http and https proxies are supported for HTTP/2)",
+			proxyURL.Scheme,
+		)
+	}
+
+	if proxyURL.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	conn, err := dial(ctx, network, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if proxyURL.Scheme == "https" {
+		var tlsConn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()}) //nolint:gosec
+
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+
+		conn = tlsConn
+	}
+
+	var req = &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+
+	if user := proxyURL.User; user != nil {
+		var password, _ = user.Password()
+
+		req.Header.Set("Proxy-Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)),
+		)
+	}
+
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("proxy CONNECT to %s failed: %s", addr, resp.Status)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// responseProtocol returns the negotiated protocol of the response.
+func responseProtocol(resp *http.Response) string {
+	if resp.ProtoMajor == 2 { //nolint:gomnd
+		if resp.TLS != nil {
+			return protocolH2
+		}
+
+		return protocolH2C
+	}
+
+	return protocolHTTP1
+}
@@ -0,0 +1,154 @@
+package addons
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// retryOptions are the options of the failed requests retrying.
+type retryOptions struct {
+	attempts       int           // the maximum number of attempts (including the first one)
+	backoff        time.Duration // the delay before the first retry (it is doubled for each next retry)
+	maxBackoff     time.Duration // the maximum delay between the attempts (Retry-After is not limited)
+	statuses       map[int]struct{}
+	onNetworkError bool
+}
+
+// defaultRetryStatuses are the response statuses that are retried by default.
+var defaultRetryStatuses = []int{ //nolint:gochecknoglobals
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// parseRetryOptions parses the `retry` option: the number of attempts or the object with the `attempts`, `backoff`
+// (ms), `maxBackoff` (ms), `on` (statuses) and `onNetworkError` properties.
+func parseRetryOptions(runtime *js.Runtime, v js.Value) retryOptions {
+	var o = retryOptions{
+		attempts:       3,                      //nolint:gomnd
+		backoff:        100 * time.Millisecond, //nolint:gomnd
+		maxBackoff:     10 * time.Second,       //nolint:gomnd
+		statuses:       make(map[int]struct{}, len(defaultRetryStatuses)),
+		onNetworkError: true,
+	}
+
+	for _, status := range defaultRetryStatuses {
+		o.statuses[status] = struct{}{}
+	}
+
+	if obj, isObject := v.(*js.Object); !isObject {
+		o.attempts = int(v.ToInteger())
+	} else {
+		if attempts := obj.Get("attempts"); attempts != nil && !js.IsUndefined(attempts) {
+			o.attempts = int(attempts.ToInteger())
+		}
+
+		if backoff := obj.Get("backoff"); backoff != nil && !js.IsUndefined(backoff) {
+			o.backoff = time.Duration(backoff.ToInteger()) * time.Millisecond
+		}
+
+		if maxBackoff := obj.Get("maxBackoff"); maxBackoff != nil && !js.IsUndefined(maxBackoff) {
+			o.maxBackoff = time.Duration(maxBackoff.ToInteger()) * time.Millisecond
+		}
+
+		if on := obj.Get("on"); on != nil && !js.IsUndefined(on) && !js.IsNull(on) {
+			var statuses []int
+
+			if err := runtime.ExportTo(on, &statuses); err != nil {
+				panic(runtime.NewTypeError("The retry.on option must be an array of status codes"))
+			}
+
+			o.statuses = make(map[int]struct{}, len(statuses))
+
+			for _, status := range statuses {
+				o.statuses[status] = struct{}{}
+			}
+		}
+
+		if onNetworkError := obj.Get("onNetworkError"); onNetworkError != nil && !js.IsUndefined(onNetworkError) {
+			o.onNetworkError = onNetworkError.ToBoolean()
+		}
+	}
+
+	if o.attempts < 1 {
+		panic(runtime.NewTypeError("The retry attempts number must be positive"))
+	}
+
+	return o
+}
+
+// retryStatus reports whether the response with the status code should be retried.
+func (o retryOptions) retryStatus(code int) bool {
+	_, ok := o.statuses[code]
+
+	return ok
+}
+
+// retryNetworkError reports whether the request that failed with the network error should be retried. Only the
+// transient errors (timeouts, refused, reset or unexpectedly closed connections) are retried, since others (e.g.
+// the disallowed redirect, invalid URL or certificate verification failure) would fail the same way again.
+func (o retryOptions) retryNetworkError(err error) bool {
+	if !o.onNetworkError {
+		return false
+	}
+
+	switch networkErrorCode(err) {
+	case errCodeTimedOut, errCodeConnRefused, errCodeConnReset:
+		return true
+	case errCodeUnknown:
+		return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) // the connection is closed by the server
+	}
+
+	return false
+}
+
+// delay returns the delay before the next attempt (the number of the failed attempt is passed). The exponential
+// backoff with jitter (a random value between the half and the full backoff) is used, unless the server asks to wait
+// using the Retry-After header.
+func (o retryOptions) delay(attempt int, header http.Header) time.Duration {
+	if retryAfter, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		return retryAfter
+	}
+
+	var backoff = o.backoff << (attempt - 1)
+
+	if backoff > o.maxBackoff || backoff < 0 { // negative on overflow
+		backoff = o.maxBackoff
+	}
+
+	if half := int64(backoff / 2); half > 0 { //nolint:gomnd
+		return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec // the jitter doesn't need a secure random
+	}
+
+	return backoff
+}
+
+// parseRetryAfter parses the Retry-After header value (the number of seconds or the HTTP date).
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,219 @@
+package addons
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// defaultStreamChunkSize is the default number of bytes returned by the `body.read()` call.
+const defaultStreamChunkSize = 64 * 1024
+
+// errBodyTooLarge is returned when the response body exceeds the `maxBodySize` limit.
+var errBodyTooLarge = errors.New("response body is too large")
+
+// readBody reads the whole response body, limited by the maximum size (zero or negative means no limit).
+func readBody(body io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(&limitedReader{r: body, left: maxSize})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// limitedReader reads up to the limit and fails with the errBodyTooLarge if there is more data (unlike the
+// io.LimitedReader, that silently stops at the limit).
+type limitedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, fmt.Errorf("%w (the limit is exceeded)", errBodyTooLarge)
+	}
+
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1] // one more byte to detect the exceeding
+	}
+
+	n, err := l.r.Read(p)
+
+	if l.left -= int64(n); l.left < 0 {
+		return n + int(l.left), fmt.Errorf("%w (the limit is exceeded)", errBodyTooLarge)
+	}
+
+	return n, err
+}
+
+// fetchBodyStream is the streamed response body (the `stream` option), that is read incrementally using `read(n)`,
+// `readLine()` or `lines()`. It is closed automatically when the end of the body is reached.
+type fetchBodyStream struct {
+	f           *Fetch
+	runtime     *js.Runtime
+	ctx         context.Context
+	timeout     time.Duration
+	signal      *AbortSignal
+	contentType string
+
+	body    io.ReadCloser
+	reader  *bufio.Reader
+	onClose []func() // called once, when the stream is closed
+	closed  bool
+}
+
+func newFetchBodyStream(
+	f *Fetch,
+	runtime *js.Runtime,
+	ctx context.Context,
+	body io.ReadCloser,
+	maxSize int64,
+	onClose ...func(),
+) *fetchBodyStream {
+	var r io.Reader = body
+
+	if maxSize > 0 {
+		r = &limitedReader{r: body, left: maxSize}
+	}
+
+	return &fetchBodyStream{
+		f:       f,
+		runtime: runtime,
+		ctx:     ctx,
+		body:    body,
+		reader:  bufio.NewReader(r),
+		onClose: onClose,
+	}
+}
+
+// Read reads up to n bytes (64 KiB by default, the available data is returned without waiting for the full chunk)
+// and returns them as an ArrayBuffer, or null when the end of the body is reached.
+func (s *fetchBodyStream) Read(call js.FunctionCall) js.Value {
+	var size = defaultStreamChunkSize
+
+	if arg := call.Argument(0); !js.IsUndefined(arg) && !js.IsNull(arg) {
+		if size = int(arg.ToInteger()); size <= 0 {
+			panic(s.runtime.NewTypeError("The number of bytes to read must be positive"))
+		}
+	}
+
+	if s.closed {
+		return js.Null()
+	}
+
+	var buf = make([]byte, size)
+
+	n, err := s.reader.Read(buf) // returns the available data, without waiting for the full buffer
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.fail(err)
+	}
+
+	if n == 0 {
+		s.Close()
+
+		return js.Null()
+	}
+
+	return s.runtime.ToValue(s.runtime.NewArrayBuffer(buf[:n]))
+}
+
+// ReadLine reads the next line (without the line ending) and returns it as a string, or null when the end of the
+// body is reached.
+func (s *fetchBodyStream) ReadLine(_ js.FunctionCall) js.Value {
+	if line, ok := s.readLine(); ok {
+		return s.runtime.ToValue(line)
+	}
+
+	return js.Null()
+}
+
+// Lines returns the iterator over the body lines (for the `for...of` loops).
+func (s *fetchBodyStream) Lines(_ js.FunctionCall) js.Value {
+	var iterator = s.runtime.NewObject()
+
+	_ = iterator.Set("next", func(js.FunctionCall) js.Value {
+		var result = s.runtime.NewObject()
+
+		if line, ok := s.readLine(); ok {
+			_ = result.Set("value", line)
+			_ = result.Set("done", false)
+		} else {
+			_ = result.Set("value", js.Undefined())
+			_ = result.Set("done", true)
+		}
+
+		return result
+	})
+
+	_ = iterator.SetSymbol(js.SymIterator, func(call js.FunctionCall) js.Value { return call.This })
+
+	return iterator
+}
+
+// Close closes the stream (the rest of the body is discarded).
+func (s *fetchBodyStream) Close() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	_ = s.body.Close()
+
+	for _, fn := range s.onClose {
+		fn()
+	}
+}
+
+// readLine reads the next line, decoded using the response charset.
+func (s *fetchBodyStream) readLine() (string, bool) {
+	if s.closed {
+		return "", false
+	}
+
+	line, err := s.reader.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.fail(err)
+	}
+
+	if len(line) == 0 {
+		s.Close()
+
+		return "", false
+	}
+
+	return decodeText(bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'}), s.contentType), true
+}
+
+// readAll reads the rest of the body.
+func (s *fetchBodyStream) readAll() []byte {
+	if s.closed {
+		return []byte{}
+	}
+
+	data, err := io.ReadAll(s.reader)
+	if err != nil {
+		s.fail(err)
+	}
+
+	s.Close()
+
+	return data
+}
+
+// fail closes the stream and throws the JS error for the body reading failure.
+func (s *fetchBodyStream) fail(err error) {
+	s.Close()
+
+	s.f.throwError(s.ctx, s.runtime, err, s.timeout, s.signal)
+}
@@ -2,23 +2,58 @@ package addons_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	js "github.com/dop251/goja"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/tarampampam/poke/internal/js/addons"
+	"github.com/tarampampam/poke/internal/log"
 )
 
-type httpClientFunc func(*http.Request) (*http.Response, error)
+func newFetchRuntime(t *testing.T, options ...addons.FetchOption) *js.Runtime {
+	t.Helper()
 
-func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+	var runtime = js.New()
+
+	runtime.SetFieldNameMapper(js.TagFieldNameMapper("json", true))
+	require.NoError(t, addons.NewFetch(context.Background(), options...).Register(runtime))
+
+	return runtime
+}
 
 func TestFetch_Register(t *testing.T) {
 	var (
 		runtime = js.New()
-		addon   = addons.NewFetch(context.Background(), nil)
+		addon   = addons.NewFetch(context.Background())
 	)
 
 	const name = "fetchSync"
@@ -26,4 +61,1896 @@ func TestFetch_Register(t *testing.T) {
 	assert.Nil(t, runtime.Get(name))
 	assert.NoError(t, addon.Register(runtime))
 	assert.NotNil(t, runtime.Get(name))
+	assert.NotNil(t, runtime.Get("cookies"))
+}
+
+func TestFetch_Cookies(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+
+		case "/a/login", "/b/login": // the cookies with the default path (/a and /b)
+			w.Header().Set("Set-Cookie", "session="+strings.Split(r.URL.Path, "/")[1])
+
+		case "/me", "/a/me", "/b/me":
+			if c, err := r.Cookie("session"); err == nil {
+				_, _ = w.Write([]byte(c.Value))
+			} else {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, script := range map[string]string{
+		"session cookie is stored and sent": `
+			if (fetchSync(baseURL + '/me').status !== 401) throw new Error('unexpected status (before login)')
+			fetchSync(baseURL + '/login')
+			if (fetchSync(baseURL + '/me').body !== 'secret') throw new Error('cookie was not sent')`,
+
+		"credentials omit": `
+			fetchSync(baseURL + '/login')
+			if (fetchSync(baseURL + '/me', {credentials: 'omit'}).status !== 401) throw new Error('cookie was sent')`,
+
+		"get, set and clear": `
+			cookies.clear()
+			if (cookies.get(baseURL).length !== 0) throw new Error('jar is not empty')
+
+			cookies.set(baseURL, 'session', 'foo')
+			if (cookies.get(baseURL)[0].name !== 'session') throw new Error('wrong cookie name')
+			if (cookies.get(baseURL)[0].value !== 'foo') throw new Error('wrong cookie value')
+			if (fetchSync(baseURL + '/me').body !== 'foo') throw new Error('cookie was not sent')
+
+			cookies.set('example.com', 'bar', 'baz', {domain: 'example.com'})
+			cookies.clear(baseURL)
+			if (cookies.get(baseURL).length !== 0) throw new Error('cookies were not cleared')
+			if (cookies.get('https://example.com').length !== 1) throw new Error('another domain cookies were cleared')
+			if (cookies.get('www.example.com')[0].value !== 'baz') throw new Error('domain cookie is not shared')`,
+
+		"clear the subdomain only": `
+			cookies.set('example.com', 'parent', '1', {domain: 'example.com'})
+			cookies.set('api.example.com', 'api', '2')
+			cookies.set('www.example.com', 'www', '3')
+			cookies.set('v2.api.example.com', 'nested', '4')
+
+			cookies.clear('api.example.com')
+
+			const names = (domain) => cookies.get(domain).map((c) => c.name).sort().join(',')
+
+			if (names('api.example.com') !== 'parent') throw new Error('wrong api cookies: ' + names('api.example.com'))
+			if (names('v2.api.example.com') !== 'parent') throw new Error('nested subdomain cookies were not cleared')
+			if (names('www.example.com') !== 'parent,www') throw new Error('sibling cookies were cleared')
+			if (names('example.com') !== 'parent') throw new Error('parent cookies were cleared')`,
+
+		"default path cookies": `
+			fetchSync(baseURL + '/a/login')
+			fetchSync(baseURL + '/b/login')
+
+			if (fetchSync(baseURL + '/a/me').body !== 'a') throw new Error('wrong /a cookie')
+			if (fetchSync(baseURL + '/b/me').body !== 'b') throw new Error('wrong /b cookie')
+			if (fetchSync(baseURL + '/me').status !== 401) throw new Error('default path cookie is sent to /')
+
+			cookies.clear(baseURL)
+
+			if (fetchSync(baseURL + '/a/me').status !== 401) throw new Error('/a cookie was not cleared')
+			if (fetchSync(baseURL + '/b/me').status !== 401) throw new Error('/b cookie was not cleared')`,
+
+		"public suffixes": `
+			cookies.set('a.co.uk', 'a', '1')
+			cookies.set('b.co.uk', 'b', '2')
+			cookies.set('a.co.uk', 'suffix', '3', {domain: 'co.uk'})
+
+			if (cookies.get('b.co.uk').length !== 1) throw new Error('cookies are shared between a.co.uk and b.co.uk')
+
+			cookies.clear('b.co.uk')
+
+			if (cookies.get('a.co.uk')[0].name !== 'a') throw new Error('a.co.uk cookies were cleared')
+			if (cookies.get('a.co.uk').length !== 1) throw new Error('public suffix cookie is set')`,
+	} {
+		script := script
+
+		t.Run(name, func(t *testing.T) {
+			_, err := runtime.RunString(`cookies.clear();` + script)
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err := runtime.RunString(`cookies.get()`)
+	assert.ErrorContains(t, err, "URL or domain is required")
+}
+
+func TestFetch_Redirects(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+
+		case "/c":
+			_, _ = w.Write([]byte("done"))
+
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, script := range map[string]string{
+		"follow (default)": `
+			const resp = fetchSync(baseURL + '/a')
+			if (resp.body !== 'done') throw new Error('wrong body')
+			if (!resp.redirected) throw new Error('must be redirected')
+			if (resp.url !== baseURL + '/c') throw new Error('wrong final url: ' + resp.url)
+			if (resp.redirects.length !== 2) throw new Error('wrong chain length')
+			if (resp.redirects[0].url !== baseURL + '/a' || resp.redirects[0].status !== 301) throw new Error('wrong 1st')
+			if (resp.redirects[1].url !== baseURL + '/b' || resp.redirects[1].status !== 302) throw new Error('wrong 2nd')`,
+
+		"no redirects": `
+			const resp = fetchSync(baseURL + '/c')
+			if (resp.redirected || resp.redirects.length !== 0) throw new Error('must not be redirected')`,
+
+		"manual": `
+			const resp = fetchSync(baseURL + '/a', {redirect: 'manual'})
+			if (resp.status !== 301) throw new Error('wrong status: ' + resp.status)
+			if (resp.redirected) throw new Error('must not be redirected')
+			if (resp.redirects.length !== 0) throw new Error('returned redirect must not be in the chain')
+			if (resp.headers['Location'] !== '/b') throw new Error('wrong location')
+			if (resp.url !== baseURL + '/a') throw new Error('wrong url')`,
+
+		"error": `
+			try { fetchSync(baseURL + '/a', {redirect: 'error'}); throw new Error('must fail') } catch (e) {
+				if (e.code !== 'EREDIRECT') throw e
+			}`,
+
+		"max redirects": `
+			if (fetchSync(baseURL + '/a', {maxRedirects: 2}).body !== 'done') throw new Error('limit is too strict')
+			try { fetchSync(baseURL + '/a', {maxRedirects: 1}); throw new Error('must fail') } catch (e) {
+				if (!e.message.includes('stopped after 1 redirects')) throw e
+			}
+			try { fetchSync(baseURL + '/loop'); throw new Error('must fail') } catch (e) {
+				if (!e.message.includes('stopped after 10 redirects')) throw e
+			}`,
+
+		"undefined options are ignored": `
+			const opts = {maxRedirects: undefined, timeout: undefined}
+			if (fetchSync(baseURL + '/a', opts).body !== 'done') throw new Error('undefined options are not ignored')
+			if (fetchSync(baseURL + '/a', {maxRedirects: null, timeout: null}).body !== 'done') throw new Error('null')`,
+	} {
+		script := script
+
+		t.Run(name, func(t *testing.T) {
+			_, err := runtime.RunString(`{` + script + `}`) // block scope for the constants
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err := runtime.RunString(`fetchSync(baseURL, {redirect: 'foo'})`)
+	assert.ErrorContains(t, err, "Unsupported redirect mode: foo")
+}
+
+func TestFetch_TimeoutAndAbort(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		case "/stream": // sends the first line and waits before the rest
+			_, _ = w.Write([]byte("line 1\n"))
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t, addons.WithFetchTimeout(time.Second*5))
+
+	require.NoError(t, addons.NewAbort(context.Background(), runtime).Register(runtime))
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantError  string
+	}{
+		"fast request": {
+			giveScript: `if (fetchSync(baseURL + '/fast', {timeout: 500}).body !== 'ok') throw new Error('wrong body')`,
+		},
+		"request timeout": {
+			giveScript: `fetchSync(baseURL + '/slow', {timeout: 50})`,
+			wantError:  "TimeoutError: The request timed out after 50ms",
+		},
+		"timeout error can be caught": {
+			giveScript: `
+				try { fetchSync(baseURL + '/slow', {timeout: 50}) } catch (e) {
+					if (e.name !== 'TimeoutError') throw new Error('wrong error name: ' + e.name)
+				}`,
+		},
+		"already aborted signal": {
+			giveScript: `fetchSync(baseURL + '/fast', {signal: AbortSignal.abort('foo')})`,
+			wantError:  "foo",
+		},
+		"timeout signal": {
+			giveScript: `fetchSync(baseURL + '/slow', {signal: AbortSignal.timeout(50)})`,
+			wantError:  "TimeoutError: signal timed out",
+		},
+		"aborted streamed body": {
+			giveScript: `
+				const controller = new AbortController();
+				const body = fetchSync(baseURL + '/stream', {stream: true, signal: controller.signal}).body;
+				if (body.readLine() !== 'line 1') throw new Error('wrong line');
+				controller.abort();
+				body.readLine()`,
+			wantError: "AbortError: signal is aborted",
+		},
+		"controller aborts the next requests": {
+			giveScript: `
+				const ctrl = new AbortController();
+				if (fetchSync(baseURL + '/fast', {signal: ctrl.signal}).body !== 'ok') throw new Error('wrong body');
+				ctrl.abort('stop');
+				fetchSync(baseURL + '/fast', {signal: ctrl.signal})`,
+			wantError: "stop",
+		},
+		"not a signal": {
+			giveScript: `fetchSync(baseURL + '/fast', {signal: {}})`,
+			wantError:  "The signal option must be an AbortSignal",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			_, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFetch_NetworkErrors(t *testing.T) {
+	var (
+		srv    = httptest.NewServer(http.NotFoundHandler())
+		tlsSrv = httptest.NewTLSServer(http.NotFoundHandler())
+		closed = srv.URL
+	)
+
+	srv.Close() // closed server refuses the connections
+
+	defer tlsSrv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	for name, tt := range map[string]struct {
+		giveURL  string
+		wantCode string
+	}{
+		"connection refused": {giveURL: closed, wantCode: "ECONNREFUSED"},
+		"host not found":     {giveURL: "http://foo.invalid", wantCode: "ENOTFOUND"},
+		"tls failure":        {giveURL: tlsSrv.URL, wantCode: "ETLS"},
+		"invalid url":        {giveURL: "http://[::1", wantCode: "EINVALIDURL"},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, runtime.Set("url", tt.giveURL))
+
+			result, err := runtime.RunString(`(() => {
+				try { fetchSync(url) } catch (e) { return [e instanceof TypeError, e.code] }
+			})()`)
+
+			require.NoError(t, err)
+			assert.Equal(t, []any{true, tt.wantCode}, result.Export())
+		})
+	}
+}
+
+// newClientCertificate generates the self-signed client certificate and key (PEM encoded).
+func newClientCertificate(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "client"}}, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestFetch_TLS(t *testing.T) {
+	var srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert} //nolint:gosec
+	srv.StartTLS()
+
+	defer srv.Close()
+
+	var (
+		runtime       = newFetchRuntime(t)
+		cert, key     = newClientCertificate(t)
+		serverCertPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+	require.NoError(t, runtime.Set("ca", string(serverCertPEM)))
+	require.NoError(t, runtime.Set("cert", cert))
+	require.NoError(t, runtime.Set("key", key))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantError  string
+	}{
+		"unknown authority": {
+			giveScript: `fetchSync(baseURL)`,
+			wantError:  "fetch failed (ETLS)",
+		},
+		"custom CA": {
+			giveScript: `
+				const resp = fetchSync(baseURL, {tls: {ca: ca}})
+				if (!resp.ok) throw new Error('wrong status')
+				if (!resp.tls.version.startsWith('TLS 1.')) throw new Error('wrong version: ' + resp.tls.version)
+				if (resp.tls.cipher === '') throw new Error('empty cipher')
+				if (!resp.tls.certificate.subject.includes('Acme Co')) throw new Error('wrong subject')
+				if (isNaN(Date.parse(resp.tls.certificate.notAfter))) throw new Error('wrong expiry')`,
+		},
+		"insecure": {
+			giveScript: `if (!fetchSync(baseURL, {tls: {insecureSkipVerify: true}}).ok) throw new Error('wrong status')`,
+		},
+		"client certificate": {
+			giveScript: `
+				if (fetchSync(baseURL, {tls: {ca: [ca], cert, key}}).body !== 'client') throw new Error('cert not sent')`,
+		},
+		"min version": {
+			giveScript: `if (fetchSync(baseURL, {tls: {ca, minVersion: '1.3'}}).tls.version !== 'TLS 1.3') throw new Error()`,
+		},
+		"wrong min version": {
+			giveScript: `fetchSync(baseURL, {tls: {minVersion: '2.0'}})`,
+			wantError:  "wrong TLS options: unsupported TLS version 2.0",
+		},
+		"cert without key": {
+			giveScript: `fetchSync(baseURL, {tls: {cert}})`,
+			wantError:  "both client certificate and key are required",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			_, err := runtime.RunString(`{` + tt.giveScript + `}`)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		var plain = httptest.NewServer(http.NotFoundHandler())
+
+		defer plain.Close()
+
+		result, err := runtime.RunString(`fetchSync('` + plain.URL + `').tls`)
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.Export())
+	})
+}
+
+func TestFetch_Timings(t *testing.T) {
+	var srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	result, err := runtime.RunString(`fetchSync(baseURL, {tls: {insecureSkipVerify: true}}).timings`)
+	require.NoError(t, err)
+
+	var timings map[string]float64
+
+	require.NoError(t, runtime.ExportTo(result, &timings))
+
+	assert.Greater(t, timings["connect"], 0.0)
+	assert.Greater(t, timings["tls"], 0.0)
+	assert.GreaterOrEqual(t, timings["ttfb"], 30.0)
+	assert.GreaterOrEqual(t, timings["download"], 0.0)
+	assert.GreaterOrEqual(t, timings["total"], timings["connect"]+timings["tls"]+timings["ttfb"])
+	assert.Zero(t, timings["dns"]) // IP address is used, so there is no DNS lookup
+}
+
+func TestFetch_BinaryBodies(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = io.Copy(w, r.Body)
+
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+			_, _ = w.Write([]byte{'c', 'a', 'f', 0xE9}) // "café"
+
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"foo":"bär"}`))
+		}
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+	}{
+		"Uint8Array body": {
+			giveScript: `Array.from(new Uint8Array(
+				fetchSync(baseURL + '/echo', {method: 'POST', body: new Uint8Array([0, 1, 255])}).arrayBuffer()
+			))`,
+			wantResult: []any{int64(0), int64(1), int64(255)},
+		},
+		"ArrayBuffer body": {
+			giveScript: `new Uint8Array(
+				fetchSync(baseURL + '/echo', {method: 'POST', body: new Uint8Array([0xFF, 0xD8, 0xFF]).buffer}).arrayBuffer()
+			)[1]`,
+			wantResult: int64(0xD8),
+		},
+		"typed array view with offset": {
+			giveScript: `fetchSync(baseURL + '/echo', {
+				method: 'POST', body: new Uint8Array([65, 66, 67, 68]).subarray(1, 3),
+			}).arrayBuffer().byteLength`,
+			wantResult: int64(2),
+		},
+		"string body": {
+			giveScript: `fetchSync(baseURL + '/echo', {method: 'POST', body: 'foo'}).text()`,
+			wantResult: "foo",
+		},
+		"response charset": {
+			giveScript: `const r = fetchSync(baseURL + '/latin1'); [r.text(), r.body, r.arrayBuffer().byteLength]`,
+			wantResult: []any{"café", "café", int64(4)},
+		},
+		"json": {
+			giveScript: `fetchSync(baseURL + '/json').json().foo`,
+			wantResult: "bär",
+		},
+		"binary body is not decoded": {
+			giveScript: `const r = fetchSync(baseURL + '/echo', {method: 'POST', body: new Uint8Array([0xFF, 0xFE])});
+				Array.from(new Uint8Array(r.arrayBuffer()))`,
+			wantResult: []any{int64(0xFF), int64(0xFE)},
+		},
+		"body property": {
+			giveScript: `const r = fetchSync(baseURL + '/json');
+				['body' in r, Object.keys(r).includes('body'), JSON.parse(JSON.stringify(r)).body, r.clone().body]`,
+			wantResult: []any{true, true, `{"foo":"bär"}`, `{"foo":"bär"}`},
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			result, err := runtime.RunString(`{` + tt.giveScript + `}`)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
+
+func TestFetch_Forms(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/multipart":
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+
+			file, header, err := r.FormFile("file")
+			require.NoError(t, err)
+
+			content, _ := io.ReadAll(file)
+
+			_, _ = w.Write([]byte(r.FormValue("name") + ":" + header.Filename + ":" + string(content)))
+
+		case "/urlencoded":
+			require.NoError(t, r.ParseForm())
+
+			_, _ = w.Write([]byte(r.Header.Get("Content-Type") + ":" + r.PostForm.Get("foo")))
+
+		case "/form-response":
+			w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+			_, _ = w.Write([]byte("a=1&b=2&a=3"))
+
+		case "/echo":
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			_, _ = io.Copy(w, r.Body)
+		}
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, addons.NewForms(runtime).Register(runtime))
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+	}{
+		"multipart": {
+			giveScript: `
+				const form = new FormData()
+				form.append('name', 'foo')
+				form.append('file', new Uint8Array([98, 97, 114]), 'bar.txt')
+				fetchSync(baseURL + '/multipart', {method: 'POST', body: form}).text()`,
+			wantResult: "foo:bar.txt:bar",
+		},
+		"urlencoded": {
+			giveScript: `fetchSync(baseURL + '/urlencoded', {method: 'POST', body: new URLSearchParams({foo: 'b&r'})}).text()`,
+			wantResult: "application/x-www-form-urlencoded;charset=UTF-8:b&r",
+		},
+		"urlencoded response": {
+			giveScript: `const form = fetchSync(baseURL + '/form-response').formData(); [form.getAll('a'), [...form.keys()]]`,
+			wantResult: []any{[]any{"1", "3"}, []any{"a", "b", "a"}},
+		},
+		"multipart response": {
+			giveScript: `
+				const form = new FormData()
+				form.append('name', 'foo')
+				form.append('file', new Uint8Array([98, 97, 114]), 'bar.txt')
+				const parsed = fetchSync(baseURL + '/echo', {method: 'POST', body: form}).formData()
+				;[parsed.get('name'), parsed.get('file').name, parsed.get('file').text()]`,
+			wantResult: []any{"foo", "bar.txt", "bar"},
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			result, err := runtime.RunString(`{` + tt.giveScript + `}`)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+
+	_, err := runtime.RunString(`fetchSync(baseURL + '/echo', {method: 'POST', body: 'foo'}).formData()`)
+	assert.ErrorContains(t, err, "Cannot parse the body as form data")
+}
+
+func TestFetch_Defaults(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.RequestURI() + " " + r.Header.Get("Authorization") + " " + r.Header.Get("X-Foo")))
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+	}{
+		"defaults from options": {
+			giveScript: `fetchSync('/users').text()`,
+			wantResult: "/api/users?lang=en Bearer token ",
+		},
+		"absolute URL is not resolved": {
+			giveScript: `fetchSync(baseURL + '/other').text()`,
+			wantResult: "/other?lang=en Bearer token ",
+		},
+		"request headers take precedence": {
+			giveScript: `fetchSync('users', {headers: {Authorization: 'Basic foo'}}).text()`,
+			wantResult: "/api/users?lang=en Basic foo ",
+		},
+		"query option": {
+			giveScript: `fetchSync('users?lang=de&page=1', {query: {page: 2, tag: ['a', 'b c']}}).text()`,
+			wantResult: "/api/users?lang=de&page=2&tag=a&tag=b+c Bearer token ",
+		},
+		"relative URL with an absolute URL in the query": {
+			giveScript: `fetchSync('/login?next=https://example.com/').text()`,
+			wantResult: "/api/login?next=https://example.com/&lang=en Bearer token ",
+		},
+		"URL query is kept as is": {
+			giveScript: `fetchSync('users?b=2&a=%7e&b=3&c=x', {query: {c: 1}}).text()`,
+			wantResult: "/api/users?b=2&a=%7e&b=3&c=1&lang=en Bearer token ",
+		},
+		"defaults changing": {
+			giveScript: `
+				const d = http.defaults({
+					baseURL: baseURL + '/v2', headers: {'X-Foo': 'bar', Authorization: null}, query: {lang: null},
+				})
+				;[d.baseURL === baseURL + '/v2', d.headers['X-Foo'], fetchSync('items').text()]`,
+			wantResult: []any{true, "bar", "/v2/items  bar"},
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = newFetchRuntime(t, addons.WithFetchDefaults(addons.FetchDefaults{
+				BaseURL: srv.URL + "/api/",
+				Headers: http.Header{"Authorization": {"Bearer token"}},
+				Query:   url.Values{"lang": {"en"}},
+			}))
+
+			require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+			result, err := runtime.RunString(`{` + tt.giveScript + `}`)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
+
+func TestFetch_Headers(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+		w.Header().Add("Link", `</page/2>; rel="next"`)
+		w.Header().Add("Link", `</page/9>; rel="last"`)
+
+		_, _ = w.Write([]byte(strings.Join(r.Header.Values("X-Multi"), "|") + " " + r.Header.Get("X-Num")))
+	}))
+
+	defer srv.Close()
+
+	var runtime = newFetchRuntime(t)
+
+	require.NoError(t, runtime.Set("baseURL", srv.URL))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"response headers methods": {
+			giveScript: `const h = fetchSync(baseURL).headers
+				;[h.get('link'), h.getAll('LINK').length, h.getSetCookie(), h.has('content-type'), h.get('x-missing')]`,
+			wantResult: []any{`</page/2>; rel="next", </page/9>; rel="last"`, int64(2), []any{"a=1", "b=2"}, true, nil},
+		},
+		"plain object view": {
+			giveScript: `const h = fetchSync(baseURL).headers
+				;[h['Content-Type'], h['content-type'], Object.keys(h).includes('Set-Cookie'), JSON.parse(JSON.stringify(h)).Link]`,
+			wantResult: []any{
+				"text/plain; charset=utf-8", "text/plain; charset=utf-8", true, `</page/2>; rel="next", </page/9>; rel="last"`,
+			},
+		},
+		"iteration": {
+			giveScript: `const h = new Headers({'X-B': '2', 'x-a': '1'}); const seen = []
+				h.forEach((value, name) => seen.push(name + '=' + value))
+				;[[...h.keys()], [...h.values()], [...h.entries()], seen]`,
+			wantResult: []any{
+				[]any{"x-a", "x-b"}, []any{"1", "2"}, []any{[]any{"x-a", "1"}, []any{"x-b", "2"}}, []any{"x-a=1", "x-b=2"},
+			},
+		},
+		"for...of loop": {
+			giveScript: `const h = new Headers([['Set-Cookie', 'a=1'], ['X-Foo', 'a'], ['set-cookie', 'b=2'], ['x-foo', 'b']])
+				const pairs = []
+				for (const [k, v] of h) pairs.push(k + ': ' + v)
+				;[pairs, h.entries().next()]`,
+			wantResult: []any{
+				[]any{"set-cookie: a=1", "set-cookie: b=2", "x-foo: a, b"},
+				map[string]any{"value": []any{"set-cookie", "a=1"}, "done": false},
+			},
+		},
+		"response headers loop": {
+			giveScript: `const names = []; for (const [name] of fetchSync(baseURL).headers) names.push(name); names`,
+			wantResult: []any{"content-length", "content-type", "date", "link", "set-cookie", "set-cookie"},
+		},
+		"headers modification": {
+			giveScript: `const h = new Headers([['X-Foo', 'a']]); h.append('x-foo', 'b'); h.set('X-Bar', 'c'); h.delete('x-baz')
+				;[h.get('x-foo'), h.get('x-bar')]`,
+			wantResult: []any{"a, b", "c"},
+		},
+		"request with Headers object": {
+			giveScript: `const h = new Headers(); h.append('X-Multi', 'a'); h.append('X-Multi', 'b')
+				fetchSync(baseURL, {headers: h}).text()`,
+			wantResult: "a|b ",
+		},
+		"request with plain object (arrays and numbers)": {
+			giveScript: `fetchSync(baseURL, {headers: {'X-Multi': ['c', 'd'], 'X-Num': 42}}).text()`,
+			wantResult: "c|d 42",
+		},
+		"wrong pairs": {
+			giveScript: `new Headers([['foo']])`,
+			wantError:  "Each header pair must be an array with exactly two elements",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			result, err := runtime.RunString(`{` + tt.giveScript + `}`)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
+
+func TestFetch_ProxyAndUnixSocket(t *testing.T) {
+	var proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("proxied " + r.URL.String()))
+	}))
+
+	defer proxy.Close()
+
+	dir, err := os.MkdirTemp("", "poke") // the socket path length is limited, so the short path is used
+	require.NoError(t, err)
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var socketPath = filepath.Join(dir, "http.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	var socketSrv = &http.Server{ //nolint:gosec
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("socket " + r.URL.RequestURI()))
+		}),
+	}
+
+	go func() { _ = socketSrv.Serve(listener) }()
+
+	defer func() { _ = socketSrv.Close() }()
+
+	for name, tt := range map[string]struct {
+		giveOptions []addons.FetchOption
+		giveScript  string
+		wantResult  any
+		wantError   string
+	}{
+		"proxy option": {
+			giveScript: `fetchSync('http://example.invalid/foo?bar=1', {proxy: proxyURL}).text()`,
+			wantResult: "proxied http://example.invalid/foo?bar=1",
+		},
+		"global proxy": {
+			giveOptions: []addons.FetchOption{
+				addons.WithFetchProxy(&url.URL{Scheme: "http", Host: proxy.Listener.Addr().String()}),
+			},
+			giveScript: `fetchSync('http://example.invalid/baz').text()`,
+			wantResult: "proxied http://example.invalid/baz",
+		},
+		"proxy disabling": {
+			giveOptions: []addons.FetchOption{addons.WithFetchProxy(&url.URL{Scheme: "http", Host: "127.0.0.1:1"})},
+			giveScript:  `fetchSync(proxyURL + '/direct', {proxy: false}).text()`,
+			wantResult:  "proxied /direct",
+		},
+		"wrong proxy": {
+			giveScript: `fetchSync('http://example.invalid/', {proxy: 'foo'})`,
+			wantError:  "wrong proxy URL foo",
+		},
+		"unix socket URL": {
+			giveScript: `fetchSync('unix://' + socketPath + ':/v1/containers?all=1').text()`,
+			wantResult: "socket /v1/containers?all=1",
+		},
+		"unix socket base URL": {
+			giveScript: `http.defaults({baseURL: 'unix://' + socketPath + ':/v1'}); fetchSync('info').text()`,
+			wantResult: "socket /v1/info",
+		},
+		"socket path option": {
+			giveScript: `fetchSync('http://docker/_ping', {socketPath}).text()`,
+			wantResult: "socket /_ping",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = newFetchRuntime(t, tt.giveOptions...)
+
+			require.NoError(t, runtime.Set("proxyURL", proxy.URL))
+			require.NoError(t, runtime.Set("socketPath", socketPath))
+
+			result, runErr := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, runErr, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, runErr)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
+
+func TestFetch_Cassette(t *testing.T) {
+	var counter int
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter++
+
+		body, _ := io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/counter", http.StatusFound)
+
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+
+		default:
+			w.Header().Set("X-Counter", strconv.Itoa(counter))
+			_, _ = w.Write([]byte(fmt.Sprintf("%s %s %s #%d", r.Method, r.URL.RequestURI(), body, counter)))
+		}
+	}))
+
+	var (
+		dir    = t.TempDir()
+		script = `[
+			fetchSync(srvURL + '/redirect').text(),
+			fetchSync(srvURL + '/counter').text(),
+			fetchSync(srvURL + '/counter', {method: 'POST', body: 'foo'}).text(),
+			fetchSync(srvURL + '/counter', {method: 'POST', body: 'bar'}).headers['X-Counter'],
+			new Uint8Array(fetchSync(srvURL + '/binary').arrayBuffer()).join(','),
+		]`
+		want = []any{"GET /counter  #2", "GET /counter  #3", "POST /counter foo #4", "5", "255,0,254"}
+	)
+
+	run := func(t *testing.T, options []addons.FetchOption, script string) (js.Value, error) {
+		t.Helper()
+
+		var runtime = newFetchRuntime(t, options...)
+
+		require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+		return runtime.RunString(script)
+	}
+
+	// recording
+	result, err := run(t, []addons.FetchOption{addons.WithFetchCassette(dir, addons.CassetteRecord)}, script)
+	require.NoError(t, err)
+	assert.Equal(t, want, result.Export())
+	assert.Equal(t, 6, counter)
+
+	found, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, found, 6) // the redirect and the same request sent twice are recorded separately
+
+	srv.Close() // the server is not needed for the replaying
+
+	t.Run("replay", func(t *testing.T) {
+		result, err = run(t, []addons.FetchOption{addons.WithFetchCassette(dir, addons.CassetteReplay)}, script)
+		require.NoError(t, err)
+		assert.Equal(t, want, result.Export())
+	})
+
+	t.Run("replay the extra requests", func(t *testing.T) {
+		var options = []addons.FetchOption{addons.WithFetchCassette(dir, addons.CassetteReplayStrict)}
+
+		result, err = run(t, options, `[1, 2, 3].map(() => fetchSync(srvURL + '/counter').text())`)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"GET /counter  #2", "GET /counter  #3", "GET /counter  #3"}, result.Export())
+	})
+
+	t.Run("replayed traffic reaches the next middlewares", func(t *testing.T) {
+		var seen []int
+
+		result, err = run(t, []addons.FetchOption{
+			addons.WithFetchCassette(dir, addons.CassetteReplayStrict),
+			addons.WithFetchMiddleware(func(next http.RoundTripper) http.RoundTripper {
+				return roundTripper(func(req *http.Request) (*http.Response, error) {
+					resp, rtErr := next.RoundTrip(req)
+					if rtErr == nil {
+						seen = append(seen, resp.StatusCode)
+					}
+
+					return resp, rtErr
+				})
+			}),
+		}, `fetchSync(srvURL + '/redirect').text()`)
+		require.NoError(t, err)
+		assert.Equal(t, "GET /counter  #2", result.Export())
+		assert.Equal(t, []int{http.StatusFound, http.StatusOK}, seen)
+	})
+
+	t.Run("not strict replay sends the unmatched requests", func(t *testing.T) {
+		var options = []addons.FetchOption{addons.WithFetchCassette(dir, addons.CassetteReplay)}
+
+		result, err = run(t, options, `try { fetchSync(srvURL + '/unknown') } catch (e) { e.code }`)
+		require.NoError(t, err)
+		assert.Equal(t, "ECONNREFUSED", result.Export())
+	})
+
+	t.Run("strict replay", func(t *testing.T) {
+		var options = []addons.FetchOption{addons.WithFetchCassette(dir, addons.CassetteReplayStrict)}
+
+		_, err = run(t, options, `fetchSync(srvURL + '/counter', {method: 'POST', body: 'baz'})`)
+		assert.ErrorContains(t, err, "fetch failed (ENOTRECORDED)")
+		assert.ErrorContains(t, err, "no recorded response for POST "+srv.URL+"/counter")
+	})
+}
+
+func TestFetch_CassetteStream(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stream": // sends the first line and waits for the client disconnection
+			_, _ = w.Write([]byte("line 1\n"))
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+
+		default:
+			_, _ = w.Write([]byte("abcdef"))
+		}
+	}))
+
+	var (
+		dir    = t.TempDir()
+		script = `
+			const body = fetchSync(srvURL + '/stream', {stream: true}).body
+			const line = body.readLine()
+			body.close()
+
+			let tooLarge = false
+			try { fetchSync(srvURL + '/large', {maxBodySize: 3}) } catch (e) { tooLarge = e.message.includes('too large') }
+
+			;[line, tooLarge]`
+	)
+
+	run := func(t *testing.T, mode addons.CassetteMode) (js.Value, error) {
+		t.Helper()
+
+		var runtime = newFetchRuntime(t, addons.WithFetchCassette(dir, mode))
+
+		require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+		return runtime.RunString(script)
+	}
+
+	var startedAt = time.Now()
+
+	result, err := run(t, addons.CassetteRecord) // the streamed body is recorded while it is read
+	require.NoError(t, err)
+	assert.Equal(t, []any{"line 1", true}, result.Export())
+	assert.Less(t, time.Since(startedAt), 3*time.Second)
+
+	found, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, found, 2)
+
+	srv.Close()
+
+	result, err = run(t, addons.CassetteReplayStrict)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"line 1", true}, result.Export())
+}
+
+func TestFetch_Debug(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/text", http.StatusFound)
+
+		case "/binary":
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+
+		case "/long":
+			_, _ = w.Write([]byte(strings.Repeat("a", 5000)))
+
+		default:
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Header().Set("X-Foo", "bar")
+			_, _ = w.Write([]byte("response body"))
+		}
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveDebug    bool
+		giveScript   string
+		wantContains []string
+		wantMissing  []string
+	}{
+		"global debug": {
+			giveDebug: true,
+			giveScript: `fetchSync(srvURL + '/text', {
+				method: 'POST',
+				body: 'request body',
+				headers: {Authorization: 'Bearer secret', Cookie: 'a=b', 'X-Bar': 'baz'},
+			})`,
+			wantContains: []string{
+				"HTTP request", "method:POST", "url:" + srv.URL + "/text", "> Authorization: [REDACTED]",
+				"> Cookie: [REDACTED]", "> X-Bar: baz", "request body",
+				"HTTP response", "status:200", "< Set-Cookie: [REDACTED]", "< X-Foo: bar", "response body",
+			},
+			wantMissing: []string{"secret"},
+		},
+		"redirects are logged": {
+			giveDebug:    true,
+			giveScript:   `fetchSync(srvURL + '/redirect')`,
+			wantContains: []string{"status:302", "url:" + srv.URL + "/redirect", "status:200", "url:" + srv.URL + "/text"},
+		},
+		"binary body": {
+			giveDebug:    true,
+			giveScript:   `fetchSync(srvURL + '/binary')`,
+			wantContains: []string{"[binary body, 3 bytes]"},
+		},
+		"long body": {
+			giveDebug:    true,
+			giveScript:   `fetchSync(srvURL + '/long')`,
+			wantContains: []string{strings.Repeat("a", 4096) + "\n", "[truncated, 5000 bytes total]"},
+			wantMissing:  []string{strings.Repeat("a", 4097)},
+		},
+		"per-request debug": {
+			giveScript:   `fetchSync(srvURL + '/text'); fetchSync(srvURL + '/binary', {debug: true})`,
+			wantContains: []string{"url:" + srv.URL + "/binary"},
+			wantMissing:  []string{"url:" + srv.URL + "/text"},
+		},
+		"per-request debug disabling": {
+			giveDebug:   true,
+			giveScript:  `fetchSync(srvURL + '/text', {debug: false})`,
+			wantMissing: []string{"HTTP request", "HTTP response"},
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf     strings.Builder
+				runtime = newFetchRuntime(t,
+					addons.WithFetchLogger(log.New(log.DebugLevel, log.WithStdOut(&buf))),
+					addons.WithFetchDebug(tt.giveDebug),
+				)
+			)
+
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+			_, err := runtime.RunString(tt.giveScript)
+			require.NoError(t, err)
+
+			var output = regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(buf.String(), "") // strip colors
+
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output, want)
+			}
+
+			for _, missing := range tt.wantMissing {
+				assert.NotContains(t, output, missing)
+			}
+		})
+	}
+}
+
+func TestFetch_Auth(t *testing.T) {
+	const (
+		realm  = "test@example.com"
+		nonce  = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+		opaque = "5ccc069c403ebaf9f0171e9517f40e41"
+	)
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/basic":
+			if user, pass, ok := r.BasicAuth(); ok {
+				_, _ = w.Write([]byte(user + ":" + pass))
+
+				return
+			}
+
+		case "/bearer":
+			_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+
+			return
+
+		case "/digest", "/digest-sha256":
+			var algorithm, newHash = "MD5", md5.New //nolint:gosec
+
+			if r.URL.Path == "/digest-sha256" {
+				algorithm, newHash = "SHA-256", sha256.New
+			}
+
+			h := func(s string) string {
+				var hh = newHash()
+
+				_, _ = hh.Write([]byte(s))
+
+				return hex.EncodeToString(hh.Sum(nil))
+			}
+
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Digest ") {
+				var params = make(map[string]string)
+
+				for _, part := range regexp.MustCompile(`(\w+)=("[^"]*"|[^,]+)`).FindAllStringSubmatch(auth, -1) {
+					params[part[1]] = strings.Trim(part[2], `"`)
+				}
+
+				var (
+					ha1  = h("user:" + realm + ":secret")
+					ha2  = h(r.Method + ":" + r.URL.RequestURI())
+					want = h(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+				)
+
+				if params["response"] == want && params["opaque"] == opaque && params["uri"] == r.URL.RequestURI() {
+					body, _ := io.ReadAll(r.Body)
+					_, _ = w.Write([]byte("digest ok " + string(body)))
+
+					return
+				}
+			}
+
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Digest realm="%s", qop="auth,auth-int", algorithm=%s, nonce="%s", opaque="%s"`,
+				realm, algorithm, nonce, opaque,
+			))
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"basic": {
+			giveScript: `fetchSync(srvURL + '/basic', {auth: {type: 'basic', username: 'user', password: 'p@ss:word'}}).text()`,
+			wantResult: "user:p@ss:word",
+		},
+		"bearer": {
+			giveScript: `fetchSync(srvURL + '/bearer', {auth: {type: 'bearer', token: 'foo.bar'}}).text()`,
+			wantResult: "Bearer foo.bar",
+		},
+		"auth overrides the header": {
+			giveScript: `fetchSync(srvURL + '/bearer', {
+				headers: {Authorization: 'Basic Zm9v'},
+				auth: {type: 'bearer', token: 'baz'},
+			}).text()`,
+			wantResult: "Bearer baz",
+		},
+		"digest": {
+			giveScript: `fetchSync(srvURL + '/digest?foo=bar', {
+				method: 'POST',
+				body: 'some body',
+				auth: {type: 'digest', username: 'user', password: 'secret'},
+			}).text()`,
+			wantResult: "digest ok some body",
+		},
+		"digest SHA-256": {
+			giveScript: `fetchSync(srvURL + '/digest-sha256', {
+				auth: {type: 'digest', username: 'user', password: 'secret'},
+			}).text()`,
+			wantResult: "digest ok ",
+		},
+		"digest wrong password": {
+			giveScript: `fetchSync(srvURL + '/digest', {auth: {type: 'digest', username: 'user', password: 'wrong'}}).status`,
+			wantResult: int64(http.StatusUnauthorized),
+		},
+		"digest without challenge": {
+			giveScript: `fetchSync(srvURL + '/basic', {auth: {type: 'digest', username: 'user', password: 'secret'}}).status`,
+			wantResult: int64(http.StatusUnauthorized),
+		},
+		"empty bearer token": {
+			giveScript: `fetchSync(srvURL + '/bearer', {auth: {type: 'bearer'}})`,
+			wantError:  "The bearer token must not be empty",
+		},
+		"missing type": {
+			giveScript: `fetchSync(srvURL + '/bearer', {auth: {token: 'foo'}})`,
+			wantError:  "The auth type is required",
+		},
+		"unsupported type": {
+			giveScript: `fetchSync(srvURL + '/bearer', {auth: {type: 'ntlm'}})`,
+			wantError:  "Unsupported auth type: ntlm",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = newFetchRuntime(t)
+
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
+
+func TestFetch_OAuth2(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []url.Values
+		counter  int
+	)
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/token":
+			require.NoError(t, r.ParseForm())
+
+			var form = r.PostForm
+
+			if user, pass, ok := r.BasicAuth(); ok {
+				form.Set("basic", user+":"+pass)
+			}
+
+			requests = append(requests, form)
+			counter++
+
+			w.Header().Set("Content-Type", "application/json")
+
+			switch {
+			case form.Get("client_secret") == "wrong":
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"Bad credentials"}`))
+
+			case form.Get("scope") == "short":
+				_, _ = w.Write([]byte(fmt.Sprintf(
+					`{"access_token":"token-%d","expires_in":"0.2","refresh_token":"refresh-%d"}`, counter, counter,
+				)))
+
+			default:
+				_, _ = w.Write([]byte(fmt.Sprintf(`{"access_token":"token-%d","expires_in":3600}`, counter)))
+			}
+
+		default:
+			_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+		}
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript   string
+		wantResult   any
+		wantError    string
+		wantRequests []url.Values
+	}{
+		"client credentials (cached)": {
+			giveScript: `{
+				const auth = {
+					type: 'oauth2', tokenURL: srvURL + '/token', clientId: 'id', clientSecret: 'secret', scope: ['a', 'b'],
+				};
+
+				[fetchSync(srvURL + '/api', {auth}).text(), fetchSync(srvURL + '/api', {auth: {...auth}}).text()]
+			}`,
+			wantResult: []any{"Bearer token-1", "Bearer token-1"},
+			wantRequests: []url.Values{{
+				"grant_type": {"client_credentials"}, "client_id": {"id"}, "client_secret": {"secret"}, "scope": {"a b"},
+			}},
+		},
+		"password grant with the header auth style": {
+			giveScript: `{
+				const client = http.oauth2({
+					tokenURL: srvURL + '/token',
+					grantType: 'password',
+					clientId: 'id',
+					clientSecret: 'secret',
+					username: 'user',
+					password: 'pass',
+					authStyle: 'header',
+				});
+
+				[client.token(), fetchSync(srvURL + '/api', {auth: client}).text()]
+			}`,
+			wantResult: []any{"token-1", "Bearer token-1"},
+			wantRequests: []url.Values{{
+				"grant_type": {"password"}, "username": {"user"}, "password": {"pass"}, "basic": {"id:secret"},
+			}},
+		},
+		"refreshing on expiry": {
+			giveScript: `{
+				const client = http.oauth2({tokenURL: srvURL + '/token', clientId: 'id', scope: 'short'});
+				const first = client.token();
+
+				process.delay(150);
+
+				[first, client.token(), client.token()]
+			}`,
+			wantResult: []any{"token-1", "token-2", "token-2"},
+			wantRequests: []url.Values{
+				{"grant_type": {"client_credentials"}, "client_id": {"id"}, "scope": {"short"}},
+				{"grant_type": {"refresh_token"}, "client_id": {"id"}, "refresh_token": {"refresh-1"}},
+			},
+		},
+		"invalidation": {
+			giveScript: `{
+				const client = http.oauth2({tokenURL: srvURL + '/token', clientId: 'id'});
+				const first = client.token();
+
+				client.invalidate();
+
+				[first, client.token()]
+			}`,
+			wantResult: []any{"token-1", "token-2"},
+		},
+		"token endpoint error": {
+			giveScript: `fetchSync(srvURL + '/api', {
+				auth: {type: 'oauth2', tokenURL: srvURL + '/token', clientId: 'id', clientSecret: 'wrong'},
+			})`,
+			wantError: "Cannot get the OAuth2 access token: token endpoint responded with 401: invalid_client Bad credentials",
+		},
+		"missing token URL": {
+			giveScript: `http.oauth2({clientId: 'id'})`,
+			wantError:  "The OAuth2 token URL (tokenURL) is required",
+		},
+		"unsupported grant type": {
+			giveScript: `http.oauth2({tokenURL: srvURL + '/token', grantType: 'implicit'})`,
+			wantError:  "Unsupported OAuth2 grant type: implicit",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			requests, counter = nil, 0
+			mu.Unlock()
+
+			var runtime = newFetchRuntime(t)
+
+			require.NoError(t, addons.NewProcess(context.Background(), runtime).Register(runtime))
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+
+			if tt.wantRequests != nil {
+				mu.Lock()
+				assert.Equal(t, tt.wantRequests, requests)
+				mu.Unlock()
+			}
+		})
+	}
+}
+
+func TestFetch_Retry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = make(map[string]int)
+		bodies   []string
+	)
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.URL.Path]++
+		var n = attempts[r.URL.Path]
+
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			bodies = append(bodies, string(body))
+		}
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/flaky": // fails twice
+			if n <= 2 {
+				w.WriteHeader(http.StatusBadGateway)
+
+				return
+			}
+
+		case "/retry-after":
+			if n == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+		case "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+
+			return
+
+		case "/reset": // closes the connection on the first attempt
+			if n == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+
+				return
+			}
+
+		case "/slow": // times out on the first attempt
+			if n == 1 {
+				time.Sleep(300 * time.Millisecond)
+			}
+
+		case "/redirect":
+			http.Redirect(w, r, "/flaky", http.StatusFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprintf("ok after %d", n)))
+	}))
+
+	defer srv.Close()
+
+	var tlsSrv = httptest.NewUnstartedServer(http.NotFoundHandler())
+
+	tlsSrv.Config.ConnState = func(_ net.Conn, state http.ConnState) { // the handshake fails, so count the connections
+		if state == http.StateNew {
+			mu.Lock()
+			attempts["tls"]++
+			mu.Unlock()
+		}
+	}
+
+	tlsSrv.StartTLS()
+
+	defer tlsSrv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript   string
+		wantResult   any
+		wantError    string
+		wantBodies   []string
+		wantDuration time.Duration
+		wantAttempts map[string]int
+	}{
+		"without retry": {
+			giveScript: `{ const r = fetchSync(srvURL + '/flaky'); [r.status, r.attempts] }`,
+			wantResult: []any{int64(http.StatusBadGateway), int64(1)},
+		},
+		"retried until success": {
+			giveScript: `{
+				const r = fetchSync(srvURL + '/flaky', {method: 'POST', body: 'foo', retry: {attempts: 5, backoff: 1}});
+
+				[r.status, r.attempts, r.text()]
+			}`,
+			wantResult: []any{int64(http.StatusOK), int64(3), "ok after 3"},
+			wantBodies: []string{"foo", "foo", "foo"},
+		},
+		"attempts number shorthand": {
+			giveScript: `{ const r = fetchSync(srvURL + '/flaky', {retry: 2}); [r.status, r.attempts] }`,
+			wantResult: []any{int64(http.StatusBadGateway), int64(2)},
+		},
+		"custom statuses": {
+			giveScript: `{ const r = fetchSync(srvURL + '/teapot', {retry: {attempts: 3, backoff: 1, on: [418]}}); r.attempts }`,
+			wantResult: int64(3),
+		},
+		"status is not retried": {
+			giveScript: `{ const r = fetchSync(srvURL + '/flaky', {retry: {attempts: 3, on: [503]}}); r.attempts }`,
+			wantResult: int64(1),
+		},
+		"retry after": {
+			giveScript:   `{ const r = fetchSync(srvURL + '/retry-after', {retry: {backoff: 1}}); [r.status, r.attempts] }`,
+			wantResult:   []any{int64(http.StatusOK), int64(2)},
+			wantDuration: time.Second,
+		},
+		"network error": {
+			giveScript: `{ const r = fetchSync(srvURL + '/reset', {retry: {backoff: 1}}); [r.text(), r.attempts] }`,
+			wantResult: []any{"ok after 2", int64(2)},
+		},
+		"network error is not retried": {
+			giveScript: `fetchSync(srvURL + '/reset', {retry: {onNetworkError: false}})`,
+			wantError:  "fetch failed",
+		},
+		"timeout": {
+			giveScript: `{ const r = fetchSync(srvURL + '/slow', {timeout: 100, retry: {backoff: 1}}); [r.text(), r.attempts] }`,
+			wantResult: []any{"ok after 2", int64(2)},
+		},
+		"disallowed redirect is not retried": {
+			giveScript:   `fetchSync(srvURL + '/redirect', {redirect: 'error', retry: {backoff: 1}})`,
+			wantError:    "EREDIRECT",
+			wantAttempts: map[string]int{"/redirect": 1},
+		},
+		"certificate verification failure is not retried": {
+			giveScript:   `fetchSync(tlsSrvURL, {retry: {backoff: 1}})`,
+			wantError:    "ETLS",
+			wantAttempts: map[string]int{"tls": 1},
+		},
+		"unsupported scheme is not retried": {
+			giveScript: `fetchSync('ftp://127.0.0.1/', {retry: {backoff: 1}})`,
+			wantError:  "unsupported protocol scheme",
+		},
+		"wrong attempts": {
+			giveScript: `fetchSync(srvURL + '/flaky', {retry: 0})`,
+			wantError:  "The retry attempts number must be positive",
+		},
+		"wrong statuses": {
+			giveScript: `fetchSync(srvURL + '/flaky', {retry: {on: 'foo'}})`,
+			wantError:  "The retry.on option must be an array of status codes",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			attempts, bodies = make(map[string]int), nil
+			mu.Unlock()
+
+			var runtime = newFetchRuntime(t)
+
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+			require.NoError(t, runtime.Set("tlsSrvURL", tlsSrv.URL))
+
+			var startedAt = time.Now()
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantAttempts != nil {
+				mu.Lock()
+				assert.Equal(t, tt.wantAttempts, attempts)
+				mu.Unlock()
+			}
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+			assert.GreaterOrEqual(t, time.Since(startedAt), tt.wantDuration)
+
+			if tt.wantBodies != nil {
+				mu.Lock()
+				assert.Equal(t, tt.wantBodies, bodies)
+				mu.Unlock()
+			}
+		})
+	}
+}
+
+func TestFetch_Protocol(t *testing.T) {
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+
+	var tlsSrv = httptest.NewUnstartedServer(handler)
+
+	tlsSrv.EnableHTTP2 = true
+	tlsSrv.StartTLS()
+
+	defer tlsSrv.Close()
+
+	var srv = httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+
+	defer srv.Close()
+
+	var tunnels atomic.Int32
+
+	var proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		conn, _, _ := w.(http.Hijacker).Hijack()
+
+		tunnels.Add(1)
+
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+		go func() { _, _ = io.Copy(target, conn); _ = target.Close() }()
+		go func() { _, _ = io.Copy(conn, target); _ = conn.Close() }()
+	}))
+
+	defer proxy.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript  string
+		wantResult  any
+		wantError   string
+		wantTunnels int32
+	}{
+		"negotiated h2": {
+			giveScript: `{
+				const r = fetchSync(tlsURL, {tls: {insecureSkipVerify: true}});
+
+				[r.protocol, r.text()]
+			}`,
+			wantResult: []any{"h2", "HTTP/2.0"},
+		},
+		"forced http1 over tls": {
+			giveScript: `{
+				const r = fetchSync(tlsURL, {protocol: 'http1', tls: {insecureSkipVerify: true}});
+
+				[r.protocol, r.text()]
+			}`,
+			wantResult: []any{"http1", "HTTP/1.1"},
+		},
+		"forced h2": {
+			giveScript: `{
+				const r = fetchSync(tlsURL, {protocol: 'h2', tls: {insecureSkipVerify: true}});
+
+				[r.protocol, r.text()]
+			}`,
+			wantResult: []any{"h2", "HTTP/2.0"},
+		},
+		"plain http1": {
+			giveScript: `{ const r = fetchSync(srvURL); [r.protocol, r.text()] }`,
+			wantResult: []any{"http1", "HTTP/1.1"},
+		},
+		"forced h2c": {
+			giveScript: `{ const r = fetchSync(srvURL, {protocol: 'h2c'}); [r.protocol, r.text()] }`,
+			wantResult: []any{"h2c", "HTTP/2.0"},
+		},
+		"connection reuse": {
+			giveScript: `{
+				const first = fetchSync(srvURL, {protocol: 'h2c'}), second = fetchSync(srvURL, {protocol: 'h2c'});
+
+				[first.connection.reused, second.connection.reused, second.connection.remoteAddress === srvURL.slice(7)]
+			}`,
+			wantResult: []any{false, true, true},
+		},
+		"forced http1 through the proxy": {
+			giveScript: `{
+				const r = fetchSync(tlsURL, {protocol: 'http1', proxy: proxyURL, tls: {insecureSkipVerify: true}});
+
+				[r.protocol, r.text()]
+			}`,
+			wantResult:  []any{"http1", "HTTP/1.1"},
+			wantTunnels: 1,
+		},
+		"forced h2 through the proxy": {
+			giveScript: `{
+				const r = fetchSync(tlsURL, {protocol: 'h2', proxy: proxyURL, tls: {insecureSkipVerify: true}});
+
+				[r.protocol, r.text()]
+			}`,
+			wantResult:  []any{"h2", "HTTP/2.0"},
+			wantTunnels: 1,
+		},
+		"forced h2c through the proxy": {
+			giveScript:  `{ const r = fetchSync(srvURL, {protocol: 'h2c', proxy: proxyURL}); [r.protocol, r.text()] }`,
+			wantResult:  []any{"h2c", "HTTP/2.0"},
+			wantTunnels: 1,
+		},
+		"h2 through the unsupported proxy": {
+			giveScript: `fetchSync(tlsURL, {protocol: 'h2', proxy: 'socks5://127.0.0.1:1'})`,
+			wantError:  "unsupported proxy scheme socks5",
+		},
+		"h2 over plain http": {
+			giveScript: `fetchSync(srvURL, {protocol: 'h2'})`,
+			wantError:  "The h2 protocol requires the https:// URL",
+		},
+		"h2c over tls": {
+			giveScript: `fetchSync(tlsURL, {protocol: 'h2c'})`,
+			wantError:  "The h2c protocol requires the http:// URL",
+		},
+		"unsupported protocol": {
+			giveScript: `fetchSync(srvURL, {protocol: 'h3'})`,
+			wantError:  "Unsupported protocol: h3",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = newFetchRuntime(t)
+
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+			require.NoError(t, runtime.Set("tlsURL", tlsSrv.URL))
+			require.NoError(t, runtime.Set("proxyURL", proxy.URL))
+
+			tunnels.Store(0)
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+			assert.Equal(t, tt.wantTunnels, tunnels.Load())
+		})
+	}
+}
+
+func TestFetch_BodyStreaming(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lines": // chunked, without the content length
+			for _, chunk := range []string{"foo\n", "bar\r\n", "baz"} {
+				_, _ = w.Write([]byte(chunk))
+				w.(http.Flusher).Flush()
+			}
+
+		case "/sized":
+			w.Header().Set("Content-Length", "10")
+			_, _ = w.Write([]byte("0123456789"))
+
+		default:
+			w.Header().Set("X-Foo", "bar")
+			_, _ = w.Write([]byte(`{"foo": "bar"}`))
+		}
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"clone": {
+			giveScript: `{
+				const r = fetchSync(srvURL), c = r.clone();
+
+				c.headers.set('X-Foo', 'baz');
+
+				[c.status, c.json().foo, c.text() === r.text(), r.headers.get('X-Foo'), c.headers.get('X-Foo')]
+			}`,
+			wantResult: []any{int64(http.StatusOK), "bar", true, "bar", "baz"},
+		},
+		"lines iteration": {
+			giveScript: `{
+				const lines = [];
+
+				for (const line of fetchSync(srvURL + '/lines', {stream: true}).body.lines()) {
+					lines.push(line);
+				}
+
+				lines
+			}`,
+			wantResult: []any{"foo", "bar", "baz"},
+		},
+		"read line": {
+			giveScript: `{
+				const body = fetchSync(srvURL + '/lines', {stream: true}).body;
+
+				[body.readLine(), body.readLine(), body.readLine(), body.readLine()]
+			}`,
+			wantResult: []any{"foo", "bar", "baz", nil},
+		},
+		"read bytes": {
+			giveScript: `{
+				const body = fetchSync(srvURL + '/sized', {stream: true}).body, chunks = [];
+
+				for (let chunk; (chunk = body.read(4)) !== null;) {
+					chunks.push(String.fromCharCode(...new Uint8Array(chunk)));
+				}
+
+				chunks
+			}`,
+			wantResult: []any{"0123", "4567", "89"},
+		},
+		"text of the rest": {
+			giveScript: `{
+				const r = fetchSync(srvURL + '/lines', {stream: true});
+
+				[r.body.readLine(), r.text()]
+			}`,
+			wantResult: []any{"foo", "bar\r\nbaz"},
+		},
+		"closed stream": {
+			giveScript: `{
+				const r = fetchSync(srvURL + '/lines', {stream: true});
+
+				r.body.close();
+
+				r.body.readLine()
+			}`,
+			wantResult: nil,
+		},
+		"clone of the stream": {
+			giveScript: `fetchSync(srvURL, {stream: true}).clone()`,
+			wantError:  "Cannot clone the streamed response",
+		},
+		"max body size": {
+			giveScript: `fetchSync(srvURL + '/sized', {maxBodySize: 10}).text()`,
+			wantResult: "0123456789",
+		},
+		"max body size exceeded (content length)": {
+			giveScript: `fetchSync(srvURL + '/sized', {maxBodySize: 9})`,
+			wantError:  "fetch failed (ETOOLARGE): response body is too large (10 bytes, the limit is 9)",
+		},
+		"max body size exceeded (chunked)": {
+			giveScript: `fetchSync(srvURL + '/lines', {maxBodySize: 5})`,
+			wantError:  "fetch failed (ETOOLARGE)",
+		},
+		"max body size exceeded (stream)": {
+			giveScript: `{
+				const body = fetchSync(srvURL + '/lines', {stream: true, maxBodySize: 5}).body;
+
+				for (const line of body.lines()) {}
+			}`,
+			wantError: "fetch failed (ETOOLARGE)",
+		},
+		"wrong max body size": {
+			giveScript: `fetchSync(srvURL, {maxBodySize: -1})`,
+			wantError:  "The maxBodySize option must not be negative",
+		},
+		"wrong read size": {
+			giveScript: `fetchSync(srvURL, {stream: true}).body.read(0)`,
+			wantError:  "The number of bytes to read must be positive",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = newFetchRuntime(t)
+
+			require.NoError(t, runtime.Set("srvURL", srv.URL))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
 }
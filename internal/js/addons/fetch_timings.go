@@ -0,0 +1,146 @@
+package addons
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// fetchTimings is the request timing breakdown (in milliseconds). When redirects are followed, the phases are
+// measured for the last request, while the total includes all of them. The DNS lookup, connect and TLS handshake
+// durations are zero for the reused connections.
+type fetchTimings struct {
+	// DNS lookup duration
+	DNS float64 `json:"dns"`
+
+	// TCP connection establishing duration
+	Connect float64 `json:"connect"`
+
+	// TLS handshake duration
+	TLS float64 `json:"tls"`
+
+	// Time to the first response byte (since the request was written)
+	TTFB float64 `json:"ttfb"`
+
+	// Response body downloading duration (since the first response byte)
+	Download float64 `json:"download"`
+
+	// Total request duration (including redirects and the body downloading)
+	Total float64 `json:"total"`
+}
+
+// fetchConnection is the connection details of the request.
+type fetchConnection struct {
+	// Whether the connection was reused (keep-alive) instead of establishing the new one
+	Reused bool `json:"reused"`
+
+	// Whether the reused connection was idle before the request
+	WasIdle bool `json:"wasIdle"`
+
+	// How long the reused connection was idle (in milliseconds)
+	IdleTime float64 `json:"idleTime"`
+
+	// Local and remote addresses of the connection
+	LocalAddress  string `json:"localAddress"`
+	RemoteAddress string `json:"remoteAddress"`
+}
+
+// timingsTracer collects the request timings (and the connection details) using the httptrace hooks (they can be
+// called from different goroutines).
+type timingsTracer struct {
+	mu sync.Mutex
+
+	start, end                time.Time
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+
+	conn fetchConnection
+}
+
+func newTimingsTracer() *timingsTracer { return &timingsTracer{start: time.Now()} }
+
+func (t *timingsTracer) set(field *time.Time) func() {
+	return func() {
+		t.mu.Lock()
+		*field = time.Now()
+		t.mu.Unlock()
+	}
+}
+
+// WithContext returns the context with the tracer attached.
+func (t *timingsTracer) WithContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(&t.dnsStart)() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone)() },
+		ConnectStart:         func(string, string) { t.set(&t.connectStart)() },
+		ConnectDone:          func(string, string, error) { t.set(&t.connectDone)() },
+		TLSHandshakeStart:    t.set(&t.tlsStart),
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.set(&t.tlsDone)() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest)() },
+		GotFirstResponseByte: t.set(&t.firstByte),
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			t.conn = fetchConnection{
+				Reused:   info.Reused,
+				WasIdle:  info.WasIdle,
+				IdleTime: float64(info.IdleTime) / float64(time.Millisecond),
+			}
+
+			if info.Conn != nil {
+				t.conn.LocalAddress = info.Conn.LocalAddr().String()
+				t.conn.RemoteAddress = info.Conn.RemoteAddr().String()
+			}
+
+			if info.Reused { // reset the values of the previous request (redirect)
+				t.dnsStart, t.dnsDone, t.connectStart, t.connectDone = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+				t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			}
+		},
+	})
+}
+
+// Done marks the request (including the body downloading) as completed.
+func (t *timingsTracer) Done() { t.set(&t.end)() }
+
+// Timings returns the collected timings.
+func (t *timingsTracer) Timings() fetchTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var end = t.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	return fetchTimings{
+		DNS:      durationMs(t.dnsStart, t.dnsDone),
+		Connect:  durationMs(t.connectStart, t.connectDone),
+		TLS:      durationMs(t.tlsStart, t.tlsDone),
+		TTFB:     durationMs(t.wroteRequest, t.firstByte),
+		Download: durationMs(t.firstByte, end),
+		Total:    durationMs(t.start, end),
+	}
+}
+
+// Connection returns the connection details of the (last) request.
+func (t *timingsTracer) Connection() fetchConnection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.conn
+}
+
+// durationMs returns the duration between the two times in milliseconds (zero if any of them is not set).
+func durationMs(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
@@ -0,0 +1,204 @@
+package addons
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	js "github.com/dop251/goja"
+)
+
+// TLSOptions are the TLS settings for the HTTP requests. The CA, certificate and key values can be the PEM encoded
+// content or the path to the file.
+type TLSOptions struct {
+	CA                 []string // additional trusted CA certificates (the system pool is used as the base)
+	Cert, Key          string   // client certificate and key pair (for mTLS)
+	MinVersion         string   // minimum TLS version: 1.0, 1.1, 1.2 or 1.3
+	ServerName         string   // SNI override
+	InsecureSkipVerify bool     // skip the server certificate verification
+}
+
+// IsEmpty checks whether no one option is set.
+func (o TLSOptions) IsEmpty() bool {
+	return len(o.CA) == 0 && o.Cert == "" && o.Key == "" && o.MinVersion == "" && o.ServerName == "" &&
+		!o.InsecureSkipVerify
+}
+
+var tlsVersions = map[string]uint16{ //nolint:gochecknoglobals
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Apply applies the options to the TLS config.
+func (o TLSOptions) Apply(cfg *tls.Config) error {
+	if len(o.CA) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, ca := range o.CA {
+			pem, readErr := readPEM(ca)
+			if readErr != nil {
+				return fmt.Errorf("CA certificate: %w", readErr)
+			}
+
+			if !pool.AppendCertsFromPEM(pem) {
+				return errors.New("CA certificate: no valid PEM certificates found")
+			}
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if o.Cert != "" || o.Key != "" {
+		if o.Cert == "" || o.Key == "" {
+			return errors.New("both client certificate and key are required")
+		}
+
+		certPEM, certErr := readPEM(o.Cert)
+		if certErr != nil {
+			return fmt.Errorf("client certificate: %w", certErr)
+		}
+
+		keyPEM, keyErr := readPEM(o.Key)
+		if keyErr != nil {
+			return fmt.Errorf("client key: %w", keyErr)
+		}
+
+		pair, pairErr := tls.X509KeyPair(certPEM, keyPEM)
+		if pairErr != nil {
+			return fmt.Errorf("client certificate: %w", pairErr)
+		}
+
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return fmt.Errorf("unsupported TLS version %s (supported: 1.0, 1.1, 1.2, 1.3)", o.MinVersion)
+		}
+
+		cfg.MinVersion = version
+	}
+
+	if o.ServerName != "" {
+		cfg.ServerName = o.ServerName
+	}
+
+	if o.InsecureSkipVerify {
+		cfg.InsecureSkipVerify = true //nolint:gosec // it is the user choice
+	}
+
+	return nil
+}
+
+// parseTLSOptions parses the `tls` request option.
+func parseTLSOptions(runtime *js.Runtime, o *js.Object) *TLSOptions {
+	var (
+		result TLSOptions
+		str    = func(name string) string {
+			if v := o.Get(name); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+				return v.String()
+			}
+
+			return ""
+		}
+	)
+
+	if v := o.Get("ca"); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+		if _, isObject := v.(*js.Object); isObject {
+			_ = runtime.ExportTo(v, &result.CA)
+		} else {
+			result.CA = []string{v.String()}
+		}
+	}
+
+	result.Cert, result.Key = str("cert"), str("key")
+	result.MinVersion, result.ServerName = str("minVersion"), str("serverName")
+
+	if v := o.Get("insecureSkipVerify"); v != nil {
+		result.InsecureSkipVerify = v.ToBoolean()
+	}
+
+	return &result
+}
+
+// readPEM returns the PEM content as is, or reads it from the file.
+func readPEM(contentOrPath string) ([]byte, error) {
+	if strings.Contains(contentOrPath, "-----BEGIN") {
+		return []byte(contentOrPath), nil
+	}
+
+	return os.ReadFile(contentOrPath)
+}
+
+// fetchTLS is the negotiated TLS connection details.
+type fetchTLS struct {
+	// TLS version (e.g. `TLS 1.3`)
+	Version string `json:"version"`
+
+	// The cipher suite name (e.g. `TLS_AES_128_GCM_SHA256`)
+	Cipher string `json:"cipher"`
+
+	// The server name, sent by the client (SNI)
+	ServerName string `json:"serverName"`
+
+	// The negotiated application protocol (ALPN, e.g. `h2`)
+	Protocol string `json:"protocol"`
+
+	// The leaf certificate of the server (null if there are no certificates)
+	Certificate *fetchCertificate `json:"certificate"`
+}
+
+type fetchCertificate struct {
+	Subject   string   `json:"subject"`
+	Issuer    string   `json:"issuer"`
+	DNSNames  []string `json:"dnsNames"`
+	NotBefore string   `json:"notBefore"` // RFC 3339
+	NotAfter  string   `json:"notAfter"`  // RFC 3339
+}
+
+func newFetchTLS(state *tls.ConnectionState) *fetchTLS {
+	if state == nil {
+		return nil
+	}
+
+	var result = &fetchTLS{
+		Version:    tlsVersionName(state.Version),
+		Cipher:     tls.CipherSuiteName(state.CipherSuite),
+		ServerName: state.ServerName,
+		Protocol:   state.NegotiatedProtocol,
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		var cert = state.PeerCertificates[0]
+
+		result.Certificate = &fetchCertificate{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  append(make([]string, 0, len(cert.DNSNames)), cert.DNSNames...),
+			NotBefore: cert.NotBefore.UTC().Format(time.RFC3339),
+			NotAfter:  cert.NotAfter.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return result
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return "TLS " + name
+		}
+	}
+
+	return fmt.Sprintf("0x%04X", version)
+}
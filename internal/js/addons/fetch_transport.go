@@ -0,0 +1,143 @@
+package addons
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithFetchProxy sets up the proxy for all the requests (the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables are used by default). It is applied to the HTTP transport, so it must be used after the
+// WithFetchTransport option.
+func WithFetchProxy(proxy *url.URL) FetchOption {
+	return func(f *Fetch) {
+		if t, ok := f.transport.(*http.Transport); ok {
+			t.Proxy = http.ProxyURL(proxy)
+		}
+	}
+}
+
+// transportOptions are the per-request transport options (the new transport is created for the request if any of
+// them is set).
+type transportOptions struct {
+	tls        *TLSOptions
+	proxy      *string // empty string disables the proxy
+	socketPath string  // Unix socket path
+	protocol   string  // forced protocol (http1, h2 or h2c)
+}
+
+func (o transportOptions) isEmpty() bool {
+	return o.tls == nil && o.proxy == nil && o.socketPath == "" && o.protocol == ""
+}
+
+// requestTransport returns the HTTP transport (based on the default one) with the request options applied, and the
+// function that must be called after the request. The transports with the forced protocol only are shared between
+// the requests (so the connections can be reused), others are closed after the request.
+func (f *Fetch) requestTransport(o transportOptions) (http.RoundTripper, func(), error) {
+	if o == (transportOptions{protocol: o.protocol}) {
+		f.protocolTransportsMu.Lock()
+		defer f.protocolTransportsMu.Unlock()
+
+		if transport, ok := f.protocolTransports[o.protocol]; ok {
+			return transport, func() {}, nil
+		}
+
+		transport, err := f.newTransport(o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		f.protocolTransports[o.protocol] = transport
+
+		return transport, func() {}, nil
+	}
+
+	transport, err := f.newTransport(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transport, func() {
+		if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+	}, nil
+}
+
+// newTransport creates the HTTP transport (based on the default one) with the options applied.
+func (f *Fetch) newTransport(o transportOptions) (http.RoundTripper, error) {
+	base, ok := f.transport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport options are not supported by the current HTTP transport")
+	}
+
+	var transport = base.Clone()
+
+	if o.tls != nil {
+		var cfg = &tls.Config{} //nolint:gosec // the minimal version is set by the user
+
+		if transport.TLSClientConfig != nil {
+			cfg = transport.TLSClientConfig.Clone()
+		}
+
+		if err := o.tls.Apply(cfg); err != nil {
+			return nil, fmt.Errorf("wrong TLS options: %w", err)
+		}
+
+		transport.TLSClientConfig = cfg
+	}
+
+	if o.proxy != nil {
+		if *o.proxy == "" {
+			transport.Proxy = nil
+		} else {
+			proxyURL, err := url.Parse(*o.proxy)
+			if err != nil || proxyURL.Host == "" {
+				return nil, fmt.Errorf("wrong proxy URL %s", *o.proxy)
+			}
+
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	if o.socketPath != "" {
+		var (
+			dialer     = &net.Dialer{}
+			socketPath = o.socketPath
+		)
+
+		transport.Proxy = nil // the proxy makes no sense for the Unix socket
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+
+	if o.protocol != "" {
+		return protocolTransport(transport, o.protocol), nil
+	}
+
+	return transport, nil
+}
+
+// unixSocketURL splits the `unix:///path/to/socket:/endpoint` URL to the socket path and the HTTP URL
+// (`http://localhost/endpoint`). The URL is returned as is, if it is not a Unix socket URL.
+func unixSocketURL(rawURL string) (socketPath, httpURL string) {
+	const prefix = "unix://"
+
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", rawURL
+	}
+
+	var path, endpoint, _ = strings.Cut(strings.TrimPrefix(rawURL, prefix), ":")
+
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/" + endpoint
+	}
+
+	return path, "http://localhost" + endpoint
+}
@@ -0,0 +1,509 @@
+package addons
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	js "github.com/dop251/goja"
+)
+
+// Forms provides the FormData and URLSearchParams, used for the form bodies sending and parsing.
+// https://developer.mozilla.org/en-US/docs/Web/API/FormData
+// https://developer.mozilla.org/en-US/docs/Web/API/URLSearchParams
+type Forms struct {
+	runtime *js.Runtime
+}
+
+func NewForms(runtime *js.Runtime) *Forms { return &Forms{runtime: runtime} }
+
+func (f *Forms) Register(runtime *js.Runtime) error {
+	for name, constructor := range map[string]func(js.ConstructorCall) *js.Object{
+		"FormData":        f.formData,
+		"URLSearchParams": f.urlSearchParams,
+	} {
+		if err := runtime.GlobalObject().DefineDataProperty(
+			name,
+			runtime.ToValue(constructor),
+			js.FLAG_FALSE, // writable
+			js.FLAG_FALSE, // configurable
+			js.FLAG_TRUE,  // enumerable
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formData is the FormData constructor.
+func (f *Forms) formData(js.ConstructorCall) *js.Object {
+	return (&FormData{runtime: f.runtime}).object()
+}
+
+// urlSearchParams is the URLSearchParams constructor. It accepts the query string, the object or the array of pairs.
+func (f *Forms) urlSearchParams(call js.ConstructorCall) *js.Object {
+	var params = &URLSearchParams{runtime: f.runtime}
+
+	if init := call.Argument(0); !js.IsUndefined(init) && !js.IsNull(init) {
+		switch v := init.Export().(type) {
+		case string:
+			entries, err := parseQuery(strings.TrimPrefix(v, "?"))
+			if err != nil {
+				panic(f.runtime.NewTypeError("Wrong query string: " + err.Error()))
+			}
+
+			params.entries = entries
+
+		case []any:
+			for _, pair := range v {
+				if p, ok := pair.([]any); ok && len(p) == 2 { //nolint:gomnd
+					params.entries = append(params.entries, formEntry{name: fmt.Sprint(p[0]), value: fmt.Sprint(p[1])})
+				} else {
+					panic(f.runtime.NewTypeError("Each pair must be an array with exactly two elements"))
+				}
+			}
+
+		default:
+			var obj = init.ToObject(f.runtime)
+
+			for _, name := range obj.Keys() {
+				params.entries = append(params.entries, formEntry{name: name, value: obj.Get(name).String()})
+			}
+		}
+	}
+
+	return params.object()
+}
+
+// parseQuery parses the query string to the list of the parameters (in the original order, unlike the
+// url.ParseQuery).
+func parseQuery(query string) ([]formEntry, error) {
+	var entries = make([]formEntry, 0)
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+
+		var name, value, _ = strings.Cut(pair, "=")
+
+		name, err := url.QueryUnescape(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, formEntry{name: name, value: value})
+	}
+
+	return entries, nil
+}
+
+// newIterator creates the iterator object (that is iterable itself, for the `for...of` loops). The next function
+// returns the next value, or false when the iteration is done.
+func newIterator(runtime *js.Runtime, next func() (js.Value, bool)) *js.Object {
+	var iterator = runtime.NewObject()
+
+	_ = iterator.Set("next", func(js.FunctionCall) js.Value {
+		var result = runtime.NewObject()
+
+		if value, ok := next(); ok {
+			_ = result.Set("value", value)
+			_ = result.Set("done", false)
+		} else {
+			_ = result.Set("value", js.Undefined())
+			_ = result.Set("done", true)
+		}
+
+		return result
+	})
+
+	_ = iterator.SetSymbol(js.SymIterator, func(call js.FunctionCall) js.Value { return call.This })
+
+	return iterator
+}
+
+// formEntry is a single form field (the file is set for the file parts only).
+type formEntry struct {
+	name, value string
+	file        *FormFile
+}
+
+// FormFile is a file part of the form.
+type FormFile struct {
+	runtime *js.Runtime
+	data    []byte
+
+	// The file name
+	Name string `json:"name"`
+
+	// The file MIME type
+	Type string `json:"type"`
+
+	// The file size in bytes
+	Size int `json:"size"`
+}
+
+// ArrayBuffer returns the file content as an ArrayBuffer.
+func (f *FormFile) ArrayBuffer() js.Value {
+	return f.runtime.ToValue(f.runtime.NewArrayBuffer(append(make([]byte, 0, len(f.data)), f.data...)))
+}
+
+// Text returns the file content as a string.
+func (f *FormFile) Text() string { return string(f.data) }
+
+// formEntries is an ordered list of the form fields with the common methods.
+type formEntries struct {
+	runtime *js.Runtime
+	entries []formEntry
+}
+
+func (e *formEntries) entryValue(entry formEntry) js.Value {
+	if entry.file != nil {
+		return e.runtime.ToValue(entry.file)
+	}
+
+	return e.runtime.ToValue(entry.value)
+}
+
+// Delete removes all the fields with the given name.
+func (e *formEntries) Delete(name string) {
+	var kept = e.entries[:0]
+
+	for _, entry := range e.entries {
+		if entry.name != name {
+			kept = append(kept, entry)
+		}
+	}
+
+	e.entries = kept
+}
+
+// Get returns the first value with the given name (null if there is no such field).
+func (e *formEntries) Get(name string) js.Value {
+	for _, entry := range e.entries {
+		if entry.name == name {
+			return e.entryValue(entry)
+		}
+	}
+
+	return js.Null()
+}
+
+// GetAll returns all the values with the given name.
+func (e *formEntries) GetAll(name string) js.Value {
+	var values = make([]any, 0)
+
+	for _, entry := range e.entries {
+		if entry.name == name {
+			values = append(values, e.entryValue(entry))
+		}
+	}
+
+	return e.runtime.NewArray(values...)
+}
+
+// Has checks whether the field with the given name exists.
+func (e *formEntries) Has(name string) bool {
+	for _, entry := range e.entries {
+		if entry.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// set replaces the first field with the given name (and removes the others), or appends the new one.
+func (e *formEntries) set(entry formEntry) {
+	var (
+		result   = make([]formEntry, 0, len(e.entries)+1)
+		replaced bool
+	)
+
+	for _, existing := range e.entries {
+		if existing.name != entry.name {
+			result = append(result, existing)
+		} else if !replaced {
+			result, replaced = append(result, entry), true
+		}
+	}
+
+	if !replaced {
+		result = append(result, entry)
+	}
+
+	e.entries = result
+}
+
+// object returns the JS object for the form (v is the FormData or URLSearchParams), iterable over the entries.
+func (e *formEntries) object(v any) *js.Object {
+	var obj = e.runtime.ToValue(v).ToObject(e.runtime)
+
+	_ = obj.SetSymbol(js.SymIterator, func(js.FunctionCall) js.Value { return e.Entries() })
+
+	return obj
+}
+
+// iterator returns the iterator over the fields (the fields, added during the iteration, are included).
+func (e *formEntries) iterator(value func(formEntry) js.Value) js.Value {
+	var i int
+
+	return newIterator(e.runtime, func() (js.Value, bool) {
+		if i >= len(e.entries) {
+			return nil, false
+		}
+
+		i++
+
+		return value(e.entries[i-1]), true
+	})
+}
+
+// Entries returns the iterator over the [name, value] pairs.
+func (e *formEntries) Entries() js.Value {
+	return e.iterator(func(entry formEntry) js.Value {
+		return e.runtime.NewArray(entry.name, e.entryValue(entry))
+	})
+}
+
+// Keys returns the iterator over the field names.
+func (e *formEntries) Keys() js.Value {
+	return e.iterator(func(entry formEntry) js.Value { return e.runtime.ToValue(entry.name) })
+}
+
+// Values returns the iterator over the field values.
+func (e *formEntries) Values() js.Value { return e.iterator(e.entryValue) }
+
+// ForEach calls the function for each field with the (value, name) arguments.
+func (e *formEntries) ForEach(fn js.Callable) {
+	for _, entry := range append([]formEntry(nil), e.entries...) {
+		if _, err := fn(js.Undefined(), e.entryValue(entry), e.runtime.ToValue(entry.name)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// FormData is a set of the form fields, that is sent as the multipart/form-data body.
+type FormData formEntries
+
+func (d *FormData) fields() *formEntries { return (*formEntries)(d) }
+
+func (d *FormData) object() *js.Object { return d.fields().object(d) }
+
+// entry creates the form entry. The binary value (ArrayBuffer or its view) is a file part.
+func (d *FormData) entry(args []js.Value) formEntry {
+	if len(args) < 2 { //nolint:gomnd
+		panic(d.runtime.NewTypeError("Field name and value are required"))
+	}
+
+	var entry = formEntry{name: args[0].String()}
+
+	if data, isBinary := binaryValue(d.runtime, args[1]); isBinary {
+		var filename = "blob"
+
+		if len(args) > 2 && !js.IsUndefined(args[2]) { //nolint:gomnd
+			filename = args[2].String()
+		}
+
+		entry.file = d.newFile(filename, "", append([]byte(nil), data...))
+	} else if file, isFile := args[1].Export().(*FormFile); isFile {
+		entry.file = file
+	} else {
+		entry.value = args[1].String()
+	}
+
+	return entry
+}
+
+func (d *FormData) newFile(name, contentType string, data []byte) *FormFile {
+	if contentType == "" {
+		if contentType = mime.TypeByExtension(filepath.Ext(name)); contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	return &FormFile{runtime: d.runtime, data: data, Name: name, Type: contentType, Size: len(data)}
+}
+
+// Append appends the field (string value, or a file part for the binary value with an optional file name).
+func (d *FormData) Append(args ...js.Value) { d.entries = append(d.entries, d.entry(args)) }
+
+// AppendFile appends the file part, read from the disk. The file name and content type can be overridden using
+// the options object ({filename, type}).
+func (d *FormData) AppendFile(name, path string, options *js.Object) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(d.runtime.NewTypeError("Cannot read the file: " + err.Error()))
+	}
+
+	var filename, contentType = filepath.Base(path), ""
+
+	if options != nil {
+		if v := options.Get("filename"); v != nil && !js.IsUndefined(v) {
+			filename = v.String()
+		}
+
+		if v := options.Get("type"); v != nil && !js.IsUndefined(v) {
+			contentType = v.String()
+		}
+	}
+
+	d.entries = append(d.entries, formEntry{name: name, file: d.newFile(filename, contentType, data)})
+}
+
+// Set replaces the field value (all the fields with the same name are removed).
+func (d *FormData) Set(args ...js.Value) { d.fields().set(d.entry(args)) }
+
+func (d *FormData) Delete(name string)          { d.fields().Delete(name) }
+func (d *FormData) Get(name string) js.Value    { return d.fields().Get(name) }
+func (d *FormData) GetAll(name string) js.Value { return d.fields().GetAll(name) }
+func (d *FormData) Has(name string) bool        { return d.fields().Has(name) }
+func (d *FormData) Entries() js.Value           { return d.fields().Entries() }
+func (d *FormData) Keys() js.Value              { return d.fields().Keys() }
+func (d *FormData) Values() js.Value            { return d.fields().Values() }
+func (d *FormData) ForEach(fn js.Callable)      { d.fields().ForEach(fn) }
+
+// encode returns the multipart/form-data body and its content type (with the boundary).
+func (d *FormData) encode() (io.Reader, string) { return encodeMultipart(d.entries) }
+
+// encodeMultipart encodes the form fields to the multipart/form-data body.
+func encodeMultipart(entries []formEntry) (io.Reader, string) {
+	var (
+		buf = new(bytes.Buffer)
+		w   = multipart.NewWriter(buf)
+	)
+
+	for _, entry := range entries {
+		if entry.file == nil {
+			_ = w.WriteField(entry.name, entry.value) // writing to the buffer never fails
+
+			continue
+		}
+
+		var header = make(textproto.MIMEHeader)
+
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     entry.name,
+			"filename": entry.file.Name,
+		}))
+		header.Set("Content-Type", entry.file.Type)
+
+		part, _ := w.CreatePart(header)
+		_, _ = part.Write(entry.file.data)
+	}
+
+	_ = w.Close()
+
+	return buf, w.FormDataContentType()
+}
+
+// URLSearchParams is a set of the query parameters, that is sent as the application/x-www-form-urlencoded body.
+type URLSearchParams formEntries
+
+func (p *URLSearchParams) fields() *formEntries { return (*formEntries)(p) }
+
+func (p *URLSearchParams) object() *js.Object { return p.fields().object(p) }
+
+// Append appends the parameter.
+func (p *URLSearchParams) Append(name, value string) {
+	p.entries = append(p.entries, formEntry{name: name, value: value})
+}
+
+// Set replaces the parameter value (all the parameters with the same name are removed).
+func (p *URLSearchParams) Set(name, value string) {
+	p.fields().set(formEntry{name: name, value: value})
+}
+
+// Sort sorts the parameters by name (the order of the values with the same name is kept).
+func (p *URLSearchParams) Sort() {
+	sort.SliceStable(p.entries, func(i, j int) bool { return p.entries[i].name < p.entries[j].name })
+}
+
+// ToString returns the query string (without the leading `?`).
+func (p *URLSearchParams) ToString() string {
+	var parts = make([]string, 0, len(p.entries))
+
+	for _, entry := range p.entries {
+		parts = append(parts, url.QueryEscape(entry.name)+"="+url.QueryEscape(entry.value))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func (p *URLSearchParams) Delete(name string)          { p.fields().Delete(name) }
+func (p *URLSearchParams) Get(name string) js.Value    { return p.fields().Get(name) }
+func (p *URLSearchParams) GetAll(name string) js.Value { return p.fields().GetAll(name) }
+func (p *URLSearchParams) Has(name string) bool        { return p.fields().Has(name) }
+func (p *URLSearchParams) Entries() js.Value           { return p.fields().Entries() }
+func (p *URLSearchParams) Keys() js.Value              { return p.fields().Keys() }
+func (p *URLSearchParams) Values() js.Value            { return p.fields().Values() }
+func (p *URLSearchParams) ForEach(fn js.Callable)      { p.fields().ForEach(fn) }
+
+// encode returns the application/x-www-form-urlencoded body and its content type.
+func (p *URLSearchParams) encode() (io.Reader, string) {
+	return strings.NewReader(p.ToString()), "application/x-www-form-urlencoded;charset=UTF-8"
+}
+
+// parseForm parses the multipart/form-data or application/x-www-form-urlencoded body to the FormData.
+func parseForm(runtime *js.Runtime, body []byte, contentType string) (*FormData, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("wrong content type: %w", err)
+	}
+
+	var form = &FormData{runtime: runtime}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		entries, parseErr := parseQuery(string(body))
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		form.entries = entries
+
+	case "multipart/form-data":
+		var r = multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+		for {
+			part, partErr := r.NextPart()
+			if partErr == io.EOF {
+				break
+			} else if partErr != nil {
+				return nil, partErr
+			}
+
+			data, readErr := io.ReadAll(part)
+			if readErr != nil {
+				return nil, readErr
+			}
+
+			if part.FileName() != "" {
+				form.entries = append(form.entries, formEntry{
+					name: part.FormName(),
+					file: form.newFile(part.FileName(), part.Header.Get("Content-Type"), data),
+				})
+			} else {
+				form.entries = append(form.entries, formEntry{name: part.FormName(), value: string(data)})
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", mediaType)
+	}
+
+	return form, nil
+}
@@ -0,0 +1,132 @@
+package addons_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	js "github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/js/addons"
+)
+
+func TestForms_Register(t *testing.T) {
+	var (
+		runtime = js.New()
+		addon   = addons.NewForms(runtime)
+	)
+
+	assert.Nil(t, runtime.Get("FormData"))
+	assert.Nil(t, runtime.Get("URLSearchParams"))
+	assert.NoError(t, addon.Register(runtime))
+	assert.NotNil(t, runtime.Get("FormData"))
+	assert.NotNil(t, runtime.Get("URLSearchParams"))
+}
+
+func TestForms(t *testing.T) {
+	var filePath = filepath.Join(t.TempDir(), "foo.txt")
+
+	require.NoError(t, os.WriteFile(filePath, []byte("file content"), 0o600))
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"search params from string": {
+			giveScript: `new URLSearchParams('?b=2&a=1&a=3&c=%26+d').toString()`,
+			wantResult: "b=2&a=1&a=3&c=%26+d",
+		},
+		"search params from object": {
+			giveScript: `const p = new URLSearchParams({foo: 'bar baz', n: 1}); [p.get('foo'), p.get('n'), p.get('x')]`,
+			wantResult: []any{"bar baz", "1", nil},
+		},
+		"search params from pairs": {
+			giveScript: `new URLSearchParams([['a', 1], ['a', 2]]).getAll('a')`,
+			wantResult: []any{"1", "2"},
+		},
+		"search params wrong pairs": {
+			giveScript: `new URLSearchParams([['a']])`,
+			wantError:  "Each pair must be an array with exactly two elements",
+		},
+		"search params modification": {
+			giveScript: `
+				const p = new URLSearchParams()
+				p.append('b', '1'); p.append('a', '2'); p.append('b', '3'); p.append('c', '&')
+				p.set('b', '4'); p.delete('x'); p.sort()
+				;[p.toString(), p.has('a'), p.has('x'), [...p.keys()]]`,
+			wantResult: []any{"a=2&b=4&c=%26", true, false, []any{"a", "b", "c"}},
+		},
+		"form data fields": {
+			giveScript: `
+				const f = new FormData()
+				f.append('a', 'foo'); f.append('a', 'bar'); f.set('b', 'baz')
+				const names = []
+				f.forEach((value, name) => names.push(name + '=' + value))
+				;[f.get('a'), f.getAll('a'), f.has('b'), names, [...f.entries()].length]`,
+			wantResult: []any{"foo", []any{"foo", "bar"}, true, []any{"a=foo", "a=bar", "b=baz"}, int64(3)},
+		},
+		"search params iteration": {
+			giveScript: `
+				const p = new URLSearchParams('b=1&a=2&b=3'), pairs = []
+				for (const [name, value] of p) pairs.push(name + ':' + value)
+				;[pairs, [...p.values()], p.entries().next()]`,
+			wantResult: []any{
+				[]any{"b:1", "a:2", "b:3"},
+				[]any{"1", "2", "3"},
+				map[string]any{"value": []any{"b", "1"}, "done": false},
+			},
+		},
+		"form data iteration": {
+			giveScript: `
+				const f = new FormData(), pairs = []
+				f.append('b', '1'); f.append('a', new Uint8Array([1]), 'a.bin')
+				for (const [name, value] of f) pairs.push(name + ':' + (typeof value === 'string' ? value : value.name))
+				;[pairs, [...f.keys()]]`,
+			wantResult: []any{[]any{"b:1", "a:a.bin"}, []any{"b", "a"}},
+		},
+		"form data binary file": {
+			giveScript: `
+				const f = new FormData()
+				f.append('image', new Uint8Array([1, 2, 3]), 'image.png')
+				const file = f.get('image')
+				;[file.name, file.type, file.size]`,
+			wantResult: []any{"image.png", "image/png", int64(3)},
+		},
+		"form data file from disk": {
+			giveScript: `
+				const f = new FormData()
+				f.appendFile('doc', filePath)
+				f.appendFile('other', filePath, {filename: 'bar.bin', type: 'application/x-foo'})
+				;[f.get('doc').name, f.get('doc').text(), f.get('other').name, f.get('other').type]`,
+			wantResult: []any{"foo.txt", "file content", "bar.bin", "application/x-foo"},
+		},
+		"form data missing file": {
+			giveScript: `new FormData().appendFile('doc', '/not/exists')`,
+			wantError:  "Cannot read the file",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var runtime = js.New()
+
+			runtime.SetFieldNameMapper(js.TagFieldNameMapper("json", true))
+			require.NoError(t, addons.NewForms(runtime).Register(runtime))
+			require.NoError(t, runtime.Set("filePath", filePath))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+}
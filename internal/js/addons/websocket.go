@@ -0,0 +1,534 @@
+package addons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	js "github.com/dop251/goja"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket ready states (https://developer.mozilla.org/en-US/docs/Web/API/WebSocket/readyState).
+const (
+	wsConnecting = 0
+	wsOpen       = 1
+	wsClosing    = 2
+	wsClosed     = 3
+)
+
+// wsMessage is the received WebSocket message.
+type wsMessage struct {
+	binary bool
+	data   []byte
+}
+
+// netDialer is the HTTP transport, that can establish the network connections for the WebSocket (unless it is
+// the *http.Transport).
+type netDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// errWebSocketNotSupported is returned when the HTTP transport cannot establish the WebSocket connections.
+var errWebSocketNotSupported = errors.New("the WebSocket connections are not supported by the current HTTP transport")
+
+// WebSocket provides the WebSocket client. The HTTP client settings (transport, cookies, defaults and timeout) are
+// shared with the fetch addon.
+// https://developer.mozilla.org/en-US/docs/Web/API/WebSocket
+type WebSocket struct {
+	ctx   context.Context
+	fetch *Fetch
+}
+
+func NewWebSocket(ctx context.Context, fetch *Fetch) *WebSocket {
+	return &WebSocket{ctx: ctx, fetch: fetch}
+}
+
+// Register registers the WebSocket constructor (with the ready state constants).
+func (w *WebSocket) Register(runtime *js.Runtime) error {
+	var constructor = runtime.ToValue(w.constructor(runtime)).ToObject(runtime)
+
+	for name, value := range map[string]int{
+		"CONNECTING": wsConnecting,
+		"OPEN":       wsOpen,
+		"CLOSING":    wsClosing,
+		"CLOSED":     wsClosed,
+	} {
+		if err := constructor.Set(name, value); err != nil {
+			return err
+		}
+	}
+
+	return runtime.GlobalObject().DefineDataProperty(
+		"WebSocket",
+		constructor,
+		js.FLAG_FALSE, // writable
+		js.FLAG_FALSE, // configurable
+		js.FLAG_TRUE,  // enumerable
+	)
+}
+
+// dialer returns the WebSocket dialer, that uses the fetch HTTP transport settings (dialing, proxy and TLS) and
+// cookies. The errWebSocketNotSupported is returned if the transport cannot be used for the WebSocket connections.
+func (w *WebSocket) dialer(timeout time.Duration, protocols []string) (*websocket.Dialer, error) {
+	var dialer = &websocket.Dialer{
+		HandshakeTimeout: timeout,
+		Subprotocols:     protocols,
+		Jar:              w.fetch.jar,
+	}
+
+	switch transport := w.fetch.transport.(type) {
+	case *http.Transport:
+		dialer.NetDialContext, dialer.Proxy, dialer.TLSClientConfig =
+			transport.DialContext, transport.Proxy, transport.TLSClientConfig
+	case netDialer: // e.g. the transport with the disabled network access
+		dialer.NetDialContext = transport.DialContext
+	default:
+		return nil, errWebSocketNotSupported
+	}
+
+	return dialer, nil
+}
+
+// webSocket is the WebSocket client. The connection is established in the constructor (synchronously), the messages
+// are received in the background and queued until the script reads them using `receive()` or `messages()`. The
+// connection is closed when the script context is canceled (e.g. the script execution time is exceeded).
+// https://developer.mozilla.org/en-US/docs/Web/API/WebSocket
+type webSocket struct {
+	runtime *js.Runtime
+	this    *js.Object
+	conn    *websocket.Conn
+	timeout time.Duration // the default timeout of the operations (zero means no timeout)
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	queue    []wsMessage
+	notify   chan struct{} // signals that the queue (or the reading state) is changed
+	readErr  error         // the reading error (set when the reading is finished)
+	finished bool          // the reading is finished (the connection is closed)
+
+	pongs  chan struct{}
+	closed bool // the closed state is applied to the JS object
+}
+
+// constructor is the `new WebSocket(url, protocols)` or `new WebSocket(url, {protocols, headers, timeout})`
+// constructor.
+func (w *WebSocket) constructor(runtime *js.Runtime) func(call js.ConstructorCall) *js.Object { //nolint:funlen
+	var f = w.fetch
+
+	return func(call js.ConstructorCall) *js.Object {
+		if js.IsUndefined(call.Argument(0)) {
+			panic(runtime.NewTypeError("The WebSocket URL is required"))
+		}
+
+		var (
+			headers   = make(http.Header)
+			protocols []string
+			timeout   = f.timeout
+		)
+
+		for name, values := range f.defaults.Headers {
+			headers[name] = append([]string(nil), values...)
+		}
+
+		if arg := call.Argument(1); !js.IsUndefined(arg) && !js.IsNull(arg) {
+			if obj, isObject := arg.(*js.Object); isObject && obj.ClassName() != "Array" {
+				if v := obj.Get("protocols"); v != nil && !js.IsUndefined(v) && !js.IsNull(v) {
+					protocols = queryValues(runtime, v)
+				}
+
+				if v := obj.Get("headers"); v != nil && !js.IsUndefined(v) {
+					for name, values := range headersFromValue(runtime, v) {
+						headers[name] = values
+					}
+				}
+
+				if v := obj.Get("timeout"); v != nil && !js.IsUndefined(v) {
+					timeout = time.Duration(v.ToInteger()) * time.Millisecond
+				}
+			} else {
+				protocols = queryValues(runtime, arg)
+			}
+		}
+
+		resolvedURL, err := resolveURL(call.Argument(0).String(), f.defaults.BaseURL, f.defaults.Query, nil)
+		if err != nil {
+			panic(newNetworkError(runtime, errCodeInvalidURL, err))
+		}
+
+		switch { // http(s) URLs are allowed (e.g. when the base URL is used)
+		case strings.HasPrefix(resolvedURL, "http://"):
+			resolvedURL = "ws://" + strings.TrimPrefix(resolvedURL, "http://")
+		case strings.HasPrefix(resolvedURL, "https://"):
+			resolvedURL = "wss://" + strings.TrimPrefix(resolvedURL, "https://")
+		}
+
+		dialer, err := w.dialer(timeout, protocols)
+		if err != nil {
+			panic(newNetworkError(runtime, errCodeUnknown, err))
+		}
+
+		var ctx, cancel = context.WithCancel(w.ctx)
+
+		conn, resp, err := dialer.DialContext(ctx, resolvedURL, headers)
+		if err != nil {
+			cancel()
+
+			if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+				err = fmt.Errorf("%w (status code %d)", err, resp.StatusCode)
+			}
+
+			if strings.Contains(err.Error(), "malformed ws or wss URL") {
+				panic(newNetworkError(runtime, errCodeInvalidURL, err))
+			}
+
+			panic(newNetworkError(runtime, networkErrorCode(err), err))
+		}
+
+		var s = &webSocket{
+			runtime: runtime,
+			this:    call.This,
+			conn:    conn,
+			timeout: timeout,
+			cancel:  cancel,
+			notify:  make(chan struct{}, 1),
+			pongs:   make(chan struct{}, 1),
+		}
+
+		conn.SetPongHandler(func(string) error {
+			select {
+			case s.pongs <- struct{}{}:
+			default:
+			}
+
+			return nil
+		})
+
+		go s.read()
+
+		go func() { // the connection is closed with the context (or when the reading is finished)
+			<-ctx.Done()
+
+			_ = conn.Close()
+		}()
+
+		for name, value := range map[string]any{
+			"url":         resolvedURL,
+			"protocol":    conn.Subprotocol(),
+			"readyState":  wsOpen,
+			"closeCode":   0,
+			"closeReason": "",
+			"send":        s.send,
+			"receive":     s.receive,
+			"messages":    s.messages,
+			"ping":        s.ping,
+			"close":       s.close,
+		} {
+			_ = call.This.Set(name, value)
+		}
+
+		_ = call.This.SetSymbol(js.SymIterator, func(js.FunctionCall) js.Value { return s.messages(js.FunctionCall{}) })
+
+		return nil
+	}
+}
+
+// read reads the messages in the background and queues them (the control frames are handled while reading).
+func (s *webSocket) read() {
+	defer s.cancel()
+
+	for {
+		kind, data, err := s.conn.ReadMessage()
+
+		s.mu.Lock()
+
+		if err != nil {
+			s.readErr, s.finished = err, true
+		} else {
+			s.queue = append(s.queue, wsMessage{binary: kind == websocket.BinaryMessage, data: data})
+		}
+
+		s.mu.Unlock()
+
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// send sends the text (string) or binary (ArrayBuffer or its view) message.
+func (s *webSocket) send(data js.Value) {
+	if s.state() != wsOpen {
+		panic(s.runtime.NewTypeError("The WebSocket is not open"))
+	}
+
+	var kind, payload = websocket.TextMessage, []byte(nil)
+
+	if b, ok := binaryValue(s.runtime, data); ok {
+		kind, payload = websocket.BinaryMessage, b
+	} else {
+		payload = []byte(data.String())
+	}
+
+	_ = s.conn.SetWriteDeadline(s.deadline(js.Undefined()))
+
+	if err := s.conn.WriteMessage(kind, payload); err != nil {
+		panic(newNetworkError(s.runtime, networkErrorCode(err), err))
+	}
+}
+
+// receive waits for the next message (up to the timeout in milliseconds, the `timeout` option is used by default)
+// and returns it, or null if the timeout is exceeded or the connection is closed.
+func (s *webSocket) receive(call js.FunctionCall) js.Value {
+	var (
+		deadline = s.deadline(call.Argument(0))
+		timeout  <-chan time.Time
+	)
+
+	if !deadline.IsZero() {
+		var timer = time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	for {
+		s.mu.Lock()
+
+		if len(s.queue) > 0 {
+			var msg = s.queue[0]
+
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+
+			var result = s.runtime.NewObject()
+
+			if msg.binary {
+				_ = result.Set("type", "binary")
+				_ = result.Set("data", s.runtime.NewArrayBuffer(msg.data))
+			} else {
+				_ = result.Set("type", "text")
+				_ = result.Set("data", string(msg.data))
+			}
+
+			return result
+		}
+
+		var finished = s.finished
+
+		s.mu.Unlock()
+
+		if finished {
+			s.finish()
+
+			return js.Null()
+		}
+
+		select {
+		case <-s.notify:
+		case <-timeout:
+			return js.Null()
+		}
+	}
+}
+
+// messages returns the iterator over the messages (each one is waited up to the timeout in milliseconds, the
+// `timeout` option is used by default). The iteration stops on timeout or when the connection is closed.
+func (s *webSocket) messages(call js.FunctionCall) js.Value {
+	var (
+		iterator = s.runtime.NewObject()
+		timeout  = call.Argument(0)
+	)
+
+	_ = iterator.Set("next", func(js.FunctionCall) js.Value {
+		var (
+			result  = s.runtime.NewObject()
+			message = s.receive(js.FunctionCall{Arguments: []js.Value{timeout}})
+		)
+
+		_ = result.Set("done", js.IsNull(message))
+
+		if !js.IsNull(message) {
+			_ = result.Set("value", message)
+		}
+
+		return result
+	})
+
+	_ = iterator.SetSymbol(js.SymIterator, func(call js.FunctionCall) js.Value { return call.This })
+
+	return iterator
+}
+
+// ping sends the ping frame and waits for the pong (up to the timeout in milliseconds, the `timeout` option is used
+// by default). The round-trip time in milliseconds is returned.
+func (s *webSocket) ping(call js.FunctionCall) js.Value {
+	if s.state() != wsOpen {
+		panic(s.runtime.NewTypeError("The WebSocket is not open"))
+	}
+
+	var payload []byte
+
+	if data := call.Argument(0); !js.IsUndefined(data) && !js.IsNull(data) {
+		payload = []byte(data.String())
+	}
+
+	select { // drop the unsolicited pong
+	case <-s.pongs:
+	default:
+	}
+
+	var (
+		deadline  = s.deadline(call.Argument(1))
+		startedAt = time.Now()
+		timeout   <-chan time.Time
+	)
+
+	if err := s.conn.WriteControl(websocket.PingMessage, payload, deadline); err != nil {
+		panic(newNetworkError(s.runtime, networkErrorCode(err), err))
+	}
+
+	if !deadline.IsZero() {
+		var timer = time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-s.pongs:
+		return s.runtime.ToValue(float64(time.Since(startedAt)) / float64(time.Millisecond))
+
+	case <-timeout:
+		panic(newNetworkError(s.runtime, errCodeTimedOut, errors.New("pong is not received")))
+	}
+}
+
+// close sends the close frame with the code (1000 by default) and the reason, and waits for the server close frame
+// (up to the `timeout`).
+func (s *webSocket) close(call js.FunctionCall) js.Value {
+	if s.state() != wsOpen {
+		return js.Undefined()
+	}
+
+	var code, reason = websocket.CloseNormalClosure, ""
+
+	if v := call.Argument(0); !js.IsUndefined(v) && !js.IsNull(v) {
+		if code = int(v.ToInteger()); code != websocket.CloseNormalClosure && (code < 3000 || code > 4999) {
+			panic(s.runtime.NewTypeError("The close code must be 1000 or in the range 3000-4999"))
+		}
+	}
+
+	if v := call.Argument(1); !js.IsUndefined(v) && !js.IsNull(v) {
+		if reason = v.String(); len(reason) > 123 { //nolint:gomnd
+			panic(s.runtime.NewTypeError("The close reason must not be longer than 123 bytes"))
+		}
+	}
+
+	_ = s.this.Set("readyState", wsClosing)
+
+	var (
+		deadline = s.deadline(js.Undefined())
+		frame    = websocket.FormatCloseMessage(code, reason)
+	)
+
+	if err := s.conn.WriteControl(websocket.CloseMessage, frame, deadline); err == nil {
+		var timeout <-chan time.Time
+
+		if !deadline.IsZero() {
+			var timer = time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+
+			timeout = timer.C
+		}
+
+	wait: // for the server close frame (the unread messages are discarded)
+		for {
+			s.mu.Lock()
+			var finished = s.finished
+			s.mu.Unlock()
+
+			if finished {
+				break
+			}
+
+			select {
+			case <-s.notify:
+			case <-timeout:
+				break wait
+			}
+		}
+	}
+
+	s.cancel()
+	s.finish()
+
+	return js.Undefined()
+}
+
+// state returns the current ready state.
+func (s *webSocket) state() int64 {
+	if s.closed {
+		return wsClosed
+	}
+
+	s.mu.Lock()
+	var finished = s.finished
+	s.mu.Unlock()
+
+	if finished {
+		s.finish()
+
+		return wsClosed
+	}
+
+	return s.this.Get("readyState").ToInteger()
+}
+
+// finish applies the closed state (the close code and reason) to the JS object.
+func (s *webSocket) finish() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+
+	s.mu.Lock()
+	var readErr = s.readErr
+	s.mu.Unlock()
+
+	var code, reason = websocket.CloseAbnormalClosure, ""
+
+	if closeErr := (*websocket.CloseError)(nil); errors.As(readErr, &closeErr) {
+		code, reason = closeErr.Code, closeErr.Text
+	}
+
+	_ = s.this.Set("readyState", wsClosed)
+	_ = s.this.Set("closeCode", code)
+	_ = s.this.Set("closeReason", reason)
+}
+
+// deadline returns the deadline for the timeout argument (zero time means no deadline).
+func (s *webSocket) deadline(timeout js.Value) time.Time {
+	var d = s.timeout
+
+	if !js.IsUndefined(timeout) && !js.IsNull(timeout) {
+		d = time.Duration(timeout.ToInteger()) * time.Millisecond
+	}
+
+	if d <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(d)
+}
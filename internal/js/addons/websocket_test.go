@@ -0,0 +1,223 @@
+package addons_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	js "github.com/dop251/goja"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarampampam/poke/internal/js/addons"
+)
+
+func TestWebSocket_Register(t *testing.T) {
+	var (
+		runtime = js.New()
+		addon   = addons.NewWebSocket(context.Background(), addons.NewFetch(context.Background()))
+	)
+
+	assert.Nil(t, runtime.Get("WebSocket"))
+	assert.NoError(t, addon.Register(runtime))
+	assert.NotNil(t, runtime.Get("WebSocket"))
+	assert.Equal(t, int64(3), runtime.Get("WebSocket").ToObject(runtime).Get("CLOSED").Export())
+}
+
+func TestWebSocket(t *testing.T) {
+	var upgrader = websocket.Upgrader{Subprotocols: []string{"chat.v2", "chat.v1"}}
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, http.Header{"X-Echo": {r.Header.Get("X-Foo")}})
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		if r.URL.Path == "/bye" {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte("bye"))
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4001, "go away"))
+			_, _, _ = conn.ReadMessage() // waits for the close frame
+
+			return
+		}
+
+		for { // echo server
+			kind, data, readErr := conn.ReadMessage()
+			if readErr != nil {
+				return
+			}
+
+			if string(data) == "header" {
+				data = []byte(r.Header.Get("X-Foo"))
+			}
+
+			if writeErr := conn.WriteMessage(kind, data); writeErr != nil {
+				return
+			}
+		}
+	}))
+
+	defer srv.Close()
+
+	for name, tt := range map[string]struct {
+		giveScript string
+		wantResult any
+		wantError  string
+	}{
+		"text and binary": {
+			giveScript: `{
+				const ws = new WebSocket(srvURL);
+
+				ws.send('foo');
+				ws.send(new Uint8Array([1, 2, 3]));
+
+				const text = ws.receive(1000), binary = ws.receive(1000);
+
+				[ws.readyState === WebSocket.OPEN, text.type, text.data, binary.type, [...new Uint8Array(binary.data)]]
+			}`,
+			wantResult: []any{true, "text", "foo", "binary", []any{int64(1), int64(2), int64(3)}},
+		},
+		"headers and protocols": {
+			giveScript: `{
+				const ws = new WebSocket(srvURL, {headers: {'X-Foo': 'bar'}, protocols: ['chat.v1', 'chat.v2']});
+
+				ws.send('header');
+
+				[ws.protocol, ws.receive(1000).data, ws.url.startsWith('ws://')]
+			}`,
+			wantResult: []any{"chat.v2", "bar", true},
+		},
+		"protocols shorthand": {
+			giveScript: `new WebSocket(srvURL, 'chat.v1').protocol`,
+			wantResult: "chat.v1",
+		},
+		"receive timeout": {
+			giveScript: `new WebSocket(srvURL).receive(50)`,
+			wantResult: nil,
+		},
+		"ping": {
+			giveScript: `{ const rtt = new WebSocket(srvURL).ping('foo', 1000); rtt >= 0 && rtt < 1000 }`,
+			wantResult: true,
+		},
+		"messages iteration": {
+			giveScript: `{
+				const ws = new WebSocket(srvURL, {timeout: 100}), data = [];
+
+				['a', 'b', 'c'].forEach((s) => ws.send(s));
+
+				for (const m of ws) {
+					data.push(m.data);
+				}
+
+				data
+			}`,
+			wantResult: []any{"a", "b", "c"},
+		},
+		"client close": {
+			giveScript: `{
+				const ws = new WebSocket(srvURL);
+
+				ws.close(3001, 'done');
+
+				[ws.readyState === WebSocket.CLOSED, ws.closeCode, ws.closeReason, ws.receive(10)]
+			}`,
+			wantResult: []any{true, int64(3001), "", nil}, // the server echoes the close code only
+		},
+		"server close": {
+			giveScript: `{
+				const ws = new WebSocket(srvURL + '/bye');
+
+				[ws.receive(1000).data, ws.receive(1000), ws.readyState, ws.closeCode, ws.closeReason]
+			}`,
+			wantResult: []any{"bye", nil, int64(3), int64(4001), "go away"},
+		},
+		"send after close": {
+			giveScript: `{ const ws = new WebSocket(srvURL); ws.close(); ws.send('foo') }`,
+			wantError:  "The WebSocket is not open",
+		},
+		"wrong close code": {
+			giveScript: `new WebSocket(srvURL).close(1001)`,
+			wantError:  "The close code must be 1000 or in the range 3000-4999",
+		},
+		"not a websocket": {
+			giveScript: `new WebSocket(srvURL.replace('ws://', 'http://') + '/', {headers: {Upgrade: 'foo'}})`,
+			wantError:  "fetch failed",
+		},
+		"connection refused": {
+			giveScript: `new WebSocket('ws://127.0.0.1:1')`,
+			wantError:  "ECONNREFUSED",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			var (
+				ctx, cancel = context.WithCancel(context.Background())
+				runtime     = js.New()
+			)
+
+			defer cancel() // not closed connections are closed with the context
+
+			runtime.SetFieldNameMapper(js.TagFieldNameMapper("json", true))
+			require.NoError(t, addons.NewWebSocket(ctx, addons.NewFetch(ctx)).Register(runtime))
+			require.NoError(t, runtime.Set("srvURL", "ws"+strings.TrimPrefix(srv.URL, "http")))
+
+			result, err := runtime.RunString(tt.giveScript)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResult, result.Export())
+		})
+	}
+
+	t.Run("closed with the context", func(t *testing.T) {
+		var (
+			ctx, cancel = context.WithCancel(context.Background())
+			runtime     = js.New()
+		)
+
+		require.NoError(t, addons.NewWebSocket(ctx, addons.NewFetch(ctx)).Register(runtime))
+		require.NoError(t, runtime.Set("srvURL", "ws"+strings.TrimPrefix(srv.URL, "http")))
+		require.NoError(t, runtime.Set("cancel", cancel))
+
+		result, err := runtime.RunString(`{
+			const ws = new WebSocket(srvURL);
+
+			cancel();
+
+			[ws.receive(1000), ws.readyState, ws.closeCode]
+		}`)
+
+		require.NoError(t, err)
+		assert.Equal(t, []any{nil, int64(3), int64(1006)}, result.Export())
+	})
+
+	t.Run("not supported transport", func(t *testing.T) {
+		var (
+			runtime   = js.New()
+			transport = roundTripper(func(*http.Request) (*http.Response, error) { return nil, io.EOF })
+			fetch     = addons.NewFetch(context.Background(), addons.WithFetchTransport(transport))
+		)
+
+		require.NoError(t, addons.NewWebSocket(context.Background(), fetch).Register(runtime))
+
+		_, err := runtime.RunString(`new WebSocket('ws://127.0.0.1:1/')`)
+		assert.ErrorContains(t, err, "WebSocket connections are not supported by the current HTTP transport")
+	})
+}
+
+// roundTripper is the http.RoundTripper function (it is neither the *http.Transport nor the network dialer).
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
@@ -0,0 +1,353 @@
+// Package checker contains the static scripts checker - it parses the script (without executing) and reports syntax
+// errors and common mistakes.
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+
+	js "github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/parser"
+
+	jsRuntime "github.com/tarampampam/poke/internal/js"
+)
+
+// Severity is an issue severity.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in the script.
+type Issue struct {
+	Severity Severity
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Severity, i.Message)
+}
+
+// Check parses the script (without executing it) and returns the found issues (sorted by position).
+func Check(name, script string) []Issue {
+	program, err := parser.ParseFile(nil, name, script, 0)
+	if err != nil {
+		var list parser.ErrorList
+
+		if errors.As(err, &list) {
+			var (
+				issues = make([]Issue, 0, len(list))
+				seen   = make(map[Issue]struct{}, len(list)) // the parser may report the same error several times
+			)
+
+			for _, e := range list {
+				var issue = Issue{
+					Severity: SeverityError,
+					Line:     e.Position.Line,
+					Column:   e.Position.Column,
+					Message:  e.Message,
+				}
+
+				if _, duplicate := seen[issue]; !duplicate {
+					seen[issue] = struct{}{}
+					issues = append(issues, issue)
+				}
+			}
+
+			return issues
+		}
+
+		return []Issue{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var c = checker{program: program, declared: make(map[string]struct{}), testNames: make(map[string]file.Position)}
+
+	walk(program, nil, c.collectDeclarations)
+	walk(program, nil, c.inspect)
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		if c.issues[i].Line == c.issues[j].Line {
+			return c.issues[i].Column < c.issues[j].Column
+		}
+
+		return c.issues[i].Line < c.issues[j].Line
+	})
+
+	return c.issues
+}
+
+type checker struct {
+	program   *ast.Program
+	declared  map[string]struct{}      // all the names declared in the script (scopes are ignored)
+	testNames map[string]file.Position // registered test names
+	issues    []Issue
+}
+
+func (c *checker) position(idx file.Idx) file.Position {
+	return c.program.File.Position(int(idx) - c.program.File.Base())
+}
+
+func (c *checker) warn(idx file.Idx, format string, args ...any) {
+	var pos = c.position(idx)
+
+	c.issues = append(c.issues, Issue{
+		Severity: SeverityWarning,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// collectDeclarations collects the names of declared variables, functions, classes and parameters.
+func (c *checker) collectDeclarations(node any, _ []any) {
+	var declare = func(target any) {
+		if target == nil || reflect.ValueOf(target).IsNil() {
+			return
+		}
+
+		walk(target, nil, func(n any, _ []any) {
+			if id, ok := n.(*ast.Identifier); ok {
+				c.declared[id.Name.String()] = struct{}{}
+			}
+		})
+	}
+
+	switch n := node.(type) {
+	case *ast.Binding:
+		declare(n.Target)
+
+	case *ast.ForDeclaration:
+		declare(n.Target)
+
+	case *ast.CatchStatement:
+		declare(n.Parameter)
+
+	case *ast.ParameterList:
+		declare(n.Rest)
+
+	case *ast.FunctionLiteral:
+		declare(n.Name)
+
+	case *ast.ClassLiteral:
+		declare(n.Name)
+	}
+}
+
+var ( //nolint:gochecknoglobals
+	testFunctions = map[string]struct{}{"test": {}, "it": {}}
+	hookFunctions = map[string]struct{}{"beforeAll": {}, "beforeEach": {}, "afterEach": {}, "afterAll": {}}
+)
+
+// inspect checks the function calls.
+func (c *checker) inspect(node any, ancestors []any) {
+	var callee ast.Expression
+
+	switch n := node.(type) {
+	case *ast.CallExpression:
+		callee = n.Callee
+
+		if name := calleeName(n); name != "" {
+			c.inspectTestsRegistration(n, name, ancestors)
+		}
+
+	case *ast.NewExpression:
+		callee = n.Callee
+
+	default:
+		return
+	}
+
+	if root := rootIdentifier(callee); root != nil {
+		var name = root.Name.String()
+
+		if _, isDeclared := c.declared[name]; !isDeclared && !isKnownGlobal(name) {
+			c.warn(root.Idx, "unknown global %s (it is not declared in the script or global.d.ts)", name)
+		}
+	}
+}
+
+func (c *checker) inspectTestsRegistration(call *ast.CallExpression, name string, ancestors []any) {
+	if _, isTest := testFunctions[name]; !isTest && name != "describe" {
+		return
+	}
+
+	if context := registrationContext(ancestors); context != "" {
+		c.warn(call.Idx0(), "%s() is called inside the %s() callback, it will not be executed as expected", name, context)
+	}
+
+	if _, isTest := testFunctions[name]; !isTest || len(call.ArgumentList) == 0 {
+		return
+	}
+
+	if str, ok := call.ArgumentList[0].(*ast.StringLiteral); ok {
+		var testName = str.Value.String()
+
+		if first, exists := c.testNames[testName]; exists {
+			c.warn(call.Idx0(),
+				"duplicate test name %q (first registered at %d:%d), the first test will be overwritten",
+				testName, first.Line, first.Column,
+			)
+		} else {
+			c.testNames[testName] = c.position(call.Idx0())
+		}
+	}
+}
+
+// registrationContext returns the name of the test or hook function, inside the callback of which the node is
+// located (the innermost one). Empty string is returned when the node is not located inside such callback.
+func registrationContext(ancestors []any) string {
+	for i := len(ancestors) - 1; i > 0; i-- {
+		switch ancestors[i].(type) {
+		case *ast.FunctionLiteral, *ast.ArrowFunctionLiteral:
+		default:
+			continue
+		}
+
+		call, isCall := ancestors[i-1].(*ast.CallExpression)
+		if !isCall {
+			continue
+		}
+
+		var name = calleeName(call)
+
+		if name == "describe" {
+			return "" // describe blocks are the right place for the tests registration
+		}
+
+		_, isTest := testFunctions[name]
+		_, isHook := hookFunctions[name]
+
+		if isTest || isHook {
+			for _, arg := range call.ArgumentList {
+				if any(arg) == ancestors[i] {
+					return name
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// calleeName returns the name of the called function, if it is called by the identifier (e.g. `foo()`).
+func calleeName(call *ast.CallExpression) string {
+	if id, ok := call.Callee.(*ast.Identifier); ok {
+		return id.Name.String()
+	}
+
+	return ""
+}
+
+// rootIdentifier returns the root identifier of the expression (e.g. `foo` for `foo.bar.baz`).
+func rootIdentifier(expr ast.Expression) *ast.Identifier {
+	for {
+		switch e := expr.(type) {
+		case *ast.Identifier:
+			return e
+
+		case *ast.DotExpression:
+			expr = e.Left
+
+		case *ast.BracketExpression:
+			expr = e.Left
+
+		default:
+			return nil
+		}
+	}
+}
+
+var ( //nolint:gochecknoglobals
+	knownGlobals     map[string]struct{}
+	knownGlobalsOnce sync.Once
+
+	dtsDeclaration = regexp.MustCompile(`(?m)^\s*(?:const|let|var|function|class)\s+([A-Za-z_$][\w$]*)`)
+)
+
+// isKnownGlobal checks whether the name is a built-in JS global or declared in the global.d.ts file.
+func isKnownGlobal(name string) bool {
+	knownGlobalsOnce.Do(func() {
+		knownGlobals = make(map[string]struct{})
+
+		var vm = js.New()
+
+		if names, err := vm.RunString("Object.getOwnPropertyNames(globalThis)"); err == nil {
+			var list []string
+
+			if err = vm.ExportTo(names, &list); err == nil {
+				for _, n := range list {
+					knownGlobals[n] = struct{}{}
+				}
+			}
+		}
+
+		for _, match := range dtsDeclaration.FindAllStringSubmatch(jsRuntime.DTS(), -1) {
+			knownGlobals[match[1]] = struct{}{}
+		}
+	})
+
+	_, ok := knownGlobals[name]
+
+	return ok
+}
+
+// skipFields contains the AST node fields that must not be walked (they duplicate other nodes).
+var skipFields = map[string]struct{}{"DeclarationList": {}, "File": {}} //nolint:gochecknoglobals
+
+// walk walks the AST (depth-first) and calls the visit function for each node (including auxiliary nodes like
+// bindings or parameter lists), with the list of node ancestors.
+func walk(node any, ancestors []any, visit func(node any, ancestors []any)) {
+	visit(node, ancestors)
+
+	var v = reflect.Indirect(reflect.ValueOf(node))
+
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	ancestors = append(ancestors[:len(ancestors):len(ancestors)], node)
+
+	for i := 0; i < v.NumField(); i++ {
+		var field = v.Type().Field(i)
+
+		if _, skip := skipFields[field.Name]; skip || !field.IsExported() {
+			continue
+		}
+
+		walkValue(v.Field(i), ancestors, visit)
+	}
+}
+
+func walkValue(v reflect.Value, ancestors []any, visit func(node any, ancestors []any)) {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), ancestors, visit)
+		}
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			walkValue(v.Elem(), ancestors, visit)
+		}
+
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			walk(v.Interface(), ancestors, visit)
+		}
+
+	case reflect.Struct:
+		if v.CanAddr() {
+			walk(v.Addr().Interface(), ancestors, visit)
+		}
+	}
+}
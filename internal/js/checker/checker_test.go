@@ -0,0 +1,74 @@
+package checker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tarampampam/poke/internal/js/checker"
+)
+
+func TestCheck_SyntaxErrors(t *testing.T) {
+	var issues = checker.Check("foo.js", "const a = 1\nconst b = (\n")
+
+	assert.Len(t, issues, 1)
+	assert.Equal(t, checker.SeverityError, issues[0].Severity)
+	assert.Equal(t, 3, issues[0].Line)
+	assert.Equal(t, 1, issues[0].Column)
+	assert.Contains(t, issues[0].Message, "Unexpected end of input")
+}
+
+func TestCheck_NoIssues(t *testing.T) {
+	assert.Empty(t, checker.Check("foo.js", `
+const resp = get('https://example.com', {headers: {foo: 'bar'}})
+
+function helper(x) { return x * 2 }
+
+class Foo { bar() { return helper(1) } }
+
+describe('foo', () => {
+  beforeEach((name) => console.log(name))
+
+  test('bar', () => {
+    const [a, {b}] = [1, {b: 2}]
+
+    assert.equals(JSON.parse('{}'), {})
+    mustBe.true(new Foo().bar() === 2)
+    assert.true(Math.abs(a - b) === 1)
+    faker.uuid()
+
+    try { process.delay(1) } catch (e) { console.error(e) }
+
+    for (const item of [1]) { item.toString() }
+  })
+
+  describe('nested', () => { it('baz', () => {}) })
+})`))
+}
+
+func TestCheck_Warnings(t *testing.T) {
+	var issues = checker.Check("foo.js", `foo()
+test('bar', () => {
+  test('baz', () => {})
+})
+it('bar', () => {})
+afterAll(() => { describe('qux', () => {}) })
+new Unknown().method()
+`)
+
+	var messages = make([]string, 0, len(issues))
+
+	for _, issue := range issues {
+		assert.Equal(t, checker.SeverityWarning, issue.Severity)
+
+		messages = append(messages, issue.String())
+	}
+
+	assert.Equal(t, []string{
+		"1:1: warning: unknown global foo (it is not declared in the script or global.d.ts)",
+		"3:3: warning: test() is called inside the test() callback, it will not be executed as expected",
+		`5:1: warning: duplicate test name "bar" (first registered at 2:1), the first test will be overwritten`,
+		"6:18: warning: describe() is called inside the afterAll() callback, it will not be executed as expected",
+		"7:5: warning: unknown global Unknown (it is not declared in the script or global.d.ts)",
+	}, messages)
+}
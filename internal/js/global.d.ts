@@ -1,30 +1,311 @@
 interface FetchSyncOptions {
   /** Request method (`GET` by default) */
   method?: 'GET' | 'HAD' | 'POST' | 'PUT' | 'PATCH' | 'DELETE' | 'CONNECT' | 'OPTIONS' | 'TRACE'
-  /** Request headers map. */
-  headers?: Record<string, string>
-  /** Request body. Body data type must match "Content-Type" header. */
-  body?: string
+  /** Request headers (arrays are sent as the repeated headers). */
+  headers?: Headers | Record<string, string | number | (string | number)[]>
+  /** Query parameters, added to the request URL (arrays are sent as the repeated parameters). */
+  query?: Record<string, string | number | boolean | (string | number | boolean)[]>
+  /** Request body (binary data is sent as is). Body data type must match "Content-Type" header. */
+  body?: string | ArrayBuffer | ArrayBufferView | FormData | URLSearchParams
+  /**
+   * Authentication (the `Authorization` header is set). The digest auth is sent after the server challenge.
+   *
+   * @example
+   * fetchSync('https://example.com', {auth: {type: 'basic', username: 'user', password: 'pass'}})
+   */
+  auth?:
+    | { type: 'basic' | 'digest'; username: string; password: string }
+    | { type: 'bearer'; token: string }
+    | ({ type: 'oauth2' } & OAuth2Options)
+    | OAuth2Client
+  /** Whether to send and store cookies using the cookie jar (`include` by default). */
+  credentials?: 'include' | 'omit'
+  /** How to handle redirects: follow them, return the redirect response as is, or fail (`follow` by default). */
+  redirect?: 'follow' | 'manual' | 'error'
+  /** The maximum number of redirects to follow (`10` by default). */
+  maxRedirects?: number
+  /** Request timeout in milliseconds (the `--fetch-timeout` value by default, `0` means no timeout). */
+  timeout?: number
+  /**
+   * Retry the failed requests with the exponential backoff and jitter (the `Retry-After` response header is respected).
+   * The number means the attempts number with the default options.
+   *
+   * @example
+   * fetchSync('https://example.com', {retry: {attempts: 5, backoff: 200, on: [502, 503]}}).attempts
+   */
+  retry?: number | {
+    /** The maximum number of attempts, including the first one (`3` by default). */
+    attempts?: number
+    /** The delay before the first retry in milliseconds, doubled for each next retry (`100` by default). */
+    backoff?: number
+    /** The maximum delay between the attempts in milliseconds (`10000` by default). */
+    maxBackoff?: number
+    /** Response statuses to retry (`[408, 429, 500, 502, 503, 504]` by default). */
+    on?: number[]
+    /**
+     * Whether to retry the transient network errors (`true` by default): `ETIMEDOUT`, `ECONNREFUSED`, `ECONNRESET`
+     * and the connections closed by the server. Others (e.g. `EREDIRECT` or `ETLS`) are thrown immediately.
+     */
+    onNetworkError?: boolean
+  }
+  /**
+   * The signal to abort the request (the `TimeoutError` or the abort reason is thrown). Since `fetchSync` blocks
+   * the script, the signal is checked before the request (and the retries) is sent, and only a timer-based signal
+   * (`AbortSignal.timeout`) can interrupt a pending request. A streamed body (`stream: true`) is also aborted by
+   * the `controller.abort()` call between the reads.
+   */
+  signal?: AbortSignal
+  /**
+   * Proxy URL (the `--proxy` value or HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used by
+   * default), `false` disables the proxy.
+   */
+  proxy?: string | false
+  /**
+   * Send the request over the Unix socket (e.g. `/var/run/docker.sock`). The `unix:///path/to/socket:/endpoint` URLs
+   * are also supported.
+   */
+  socketPath?: string
+  /**
+   * Force the HTTP protocol: `http1` (HTTP/1.1 only), `h2` (HTTP/2 over TLS, `https://` URLs only) or `h2c` (HTTP/2
+   * over the plain TCP with the prior knowledge, `http://` URLs only). By default, HTTP/2 is negotiated over TLS.
+   * The `proxy` option is respected (HTTP/2 is tunneled using the CONNECT method). HTTP/3 is not supported.
+   */
+  protocol?: 'http1' | 'h2' | 'h2c'
+  /**
+   * The maximum response body size in bytes (no limit by default). The `TypeError` with the `ETOOLARGE` code is
+   * thrown when the body exceeds it (for the streamed bodies - on reading).
+   */
+  maxBodySize?: number
+  /**
+   * Do not read the response body, but stream it: the `body` property becomes the `FetchSyncBodyStream` to read the
+   * body incrementally (the timeout limits the whole body reading). `text()`, `json()` and others read the rest of
+   * the stream.
+   *
+   * @example
+   * for (const line of fetchSync('https://example.com/huge.ndjson', {stream: true}).body.lines()) {
+   *   console.log(JSON.parse(line))
+   * }
+   */
+  stream?: boolean
+  /**
+   * Log the request and response (headers and truncated bodies, sensitive headers are redacted) at the debug level.
+   * Overrides the `--http-debug` flag value.
+   */
+  debug?: boolean
+  /** TLS options (they are merged with the `--tls-*` values). Certificates and keys are PEM contents or file paths. */
+  tls?: {
+    /** Additional trusted CA certificate(s). */
+    ca?: string | string[]
+    /** Client certificate (for mTLS). */
+    cert?: string
+    /** Client certificate key (for mTLS). */
+    key?: string
+    minVersion?: '1.0' | '1.1' | '1.2' | '1.3'
+    /** Server name (SNI) override. */
+    serverName?: string
+    /** Skip the server certificate verification. */
+    insecureSkipVerify?: boolean
+  }
+}
+
+interface OAuth2Options {
+  /** The token endpoint URL. */
+  tokenURL: string
+  /** Grant type (`client_credentials` by default). */
+  grantType?: 'client_credentials' | 'password'
+  clientId?: string
+  clientSecret?: string
+  scope?: string | string[]
+  /** Resource owner username (for the `password` grant). */
+  username?: string
+  /** Resource owner password (for the `password` grant). */
+  password?: string
+  /** How the client credentials are sent: in the request body (default) or using the basic auth header. */
+  authStyle?: 'body' | 'header'
+}
+
+interface OAuth2Client {
+  /** Returns the access token (it is requested if missing or expired). */
+  token(): string
+  /** Drops the cached tokens, so the next request gets the new one. */
+  invalidate(): void
+}
+
+/** The file part of the form. */
+interface FormFile {
+  readonly name: string
+  /** MIME type. */
+  readonly type: string
+  /** Size in bytes. */
+  readonly size: number
+  arrayBuffer(): ArrayBuffer
+  text(): string
+}
+
+interface AbortSignal {
+  /** Whether the signal is aborted. */
+  readonly aborted: boolean
+  /** The abort reason (`undefined` if the signal is not aborted). */
+  readonly reason: unknown
+  /** Throws the abort reason if the signal is aborted. */
+  throwIfAborted(): void
+}
+
+interface EventSourceOptions {
+  /** Request headers (merged with the `http.defaults()` headers). */
+  headers?: Headers | Record<string, string | number | (string | number)[]>
+  /**
+   * The default waiting timeout of the `read()`, `listen()` and `events()` methods in milliseconds (the fetch timeout
+   * is used by default), `0` means no timeout.
+   */
+  timeout?: number
+  /** Reconnect when the stream is ended or the network error is occurred (`true` by default). */
+  reconnect?: boolean
+  /** The reconnection delay in milliseconds (`3000` by default), the server can change it using the `retry` field. */
+  reconnectDelay?: number
+  /** The initial `Last-Event-ID` header value. */
+  lastEventId?: string
+}
+
+interface EventSourceEvent {
+  /** The last event ID (the `id` field of this or one of the previous events). */
+  readonly id: string
+  /** The event type (the `event` field, `message` by default). */
+  readonly event: string
+  /** The event data (multiple `data` fields are joined with the new line). */
+  readonly data: string
+  /** The reconnection delay in milliseconds (the `retry` field), `0` if it is not set. */
+  readonly retry: number
+}
+
+interface EventSourceErrorEvent {
+  readonly event: 'error'
+  /** The error message (the connection failure reason). */
+  readonly message: string
+}
+
+interface WebSocketOptions {
+  /** Subprotocols to negotiate (the negotiated one is available as the `protocol` property). */
+  protocols?: string | string[]
+  /** Handshake request headers (merged with the `http.defaults()` headers). */
+  headers?: Headers | Record<string, string | number | (string | number)[]>
+  /**
+   * The default timeout of the handshake, `send()`, `receive()`, `ping()` and `close()` in milliseconds (the fetch
+   * timeout is used by default), `0` means no timeout.
+   */
+  timeout?: number
+}
+
+interface WebSocketMessage {
+  /** The message type. */
+  readonly type: 'text' | 'binary'
+  /** The message data (a string for the text messages, an ArrayBuffer for the binary ones). */
+  readonly data: string | ArrayBuffer
+}
+
+interface FetchSyncBodyStream {
+  /**
+   * Reads up to `size` bytes (`65536` by default), returns `null` when the end of the body is reached. The available
+   * data is returned without waiting for the full chunk.
+   */
+  read(size?: number): ArrayBuffer | null
+  /** Reads the next line (without the line ending), returns `null` when the end of the body is reached. */
+  readLine(): string | null
+  /** Returns the iterator over the body lines (without the line endings). */
+  lines(): IterableIterator<string>
+  /** Closes the stream (the rest of the body is discarded). It is closed automatically at the end of the body. */
+  close(): void
+}
+
+interface FetchSyncStreamResponse extends Omit<FetchSyncResponse, 'body' | 'clone'> {
+  /** The streamed body. */
+  readonly body: FetchSyncBodyStream
 }
 
 interface FetchSyncResponse {
-  /** Body contents. */
+  /** Body contents, decoded using the response charset on the first access (the same as `text()` returns). */
   readonly body: string
-  /** Response headers map. */
-  readonly headers: Record<string, string>
+  /** Response headers. Header values (joined with ", ") can be also read as the properties, case-insensitively. */
+  readonly headers: Headers & Record<string, string>
   /** A boolean indicating whether the response was successful (status in the range 200 – 299) or not. */
   readonly ok: boolean
+  /** Indicates whether the response is the result of the followed redirect(s). */
+  readonly redirected: boolean
+  /** The followed redirects chain (URLs and status codes, in order), empty for the `manual` and `error` modes. */
+  readonly redirects: ReadonlyArray<{ readonly url: string; readonly status: number }>
   /** The status code of the response. */
   readonly status: number
   /** The status message corresponding to the status code. */
   readonly statusText: string
-  /** The URL of the response. */
+  /** The URL of the response (the final one, after the redirects). */
   readonly url: string
-  /** Returns body data as an ArrayBuffer */
+  /** The negotiated TLS connection details (`null` for the plain HTTP). */
+  readonly tls: {
+    /** TLS version (e.g. `TLS 1.3`). */
+    readonly version: string
+    /** Cipher suite name (e.g. `TLS_AES_128_GCM_SHA256`). */
+    readonly cipher: string
+    readonly serverName: string
+    /** Negotiated application protocol (ALPN, e.g. `h2`). */
+    readonly protocol: string
+    /** The server (leaf) certificate. */
+    readonly certificate: {
+      readonly subject: string
+      readonly issuer: string
+      readonly dnsNames: string[]
+      /** RFC 3339 date. */
+      readonly notBefore: string
+      /** RFC 3339 date. */
+      readonly notAfter: string
+    } | null
+  } | null
+  /**
+   * The request timing breakdown in milliseconds. When redirects are followed, the phases are measured for the last
+   * request, while the total includes all of them. DNS, connect and TLS are zero for the reused connections.
+   *
+   * @example
+   * assert.true(fetchSync('https://example.com').timings.total < 300)
+   */
+  readonly timings: {
+    /** DNS lookup. */
+    readonly dns: number
+    /** TCP connection establishing. */
+    readonly connect: number
+    /** TLS handshake. */
+    readonly tls: number
+    /** Time to the first response byte (since the request was sent). */
+    readonly ttfb: number
+    /** Response body downloading. */
+    readonly download: number
+    /** Total request duration. */
+    readonly total: number
+  }
+  /** The number of the sent attempts (more than one if the request was retried, see the `retry` option). */
+  readonly attempts: number
+  /** The negotiated protocol (`http1`, `h2` or `h2c`). */
+  readonly protocol: 'http1' | 'h2' | 'h2c'
+  /** The connection details (of the last request, when redirects are followed). */
+  readonly connection: {
+    /** Whether the connection was reused (keep-alive) instead of establishing the new one. */
+    readonly reused: boolean
+    /** Whether the reused connection was idle before the request. */
+    readonly wasIdle: boolean
+    /** How long the reused connection was idle (in milliseconds). */
+    readonly idleTime: number
+    /** Local address of the connection (e.g. `127.0.0.1:54321`). */
+    readonly localAddress: string
+    /** Remote address of the connection (e.g. `93.184.216.34:443`). */
+    readonly remoteAddress: string
+  }
+  /** Returns the raw body bytes as an ArrayBuffer */
   arrayBuffer(): ArrayBuffer
+  /** Returns a copy of the response (the streamed responses cannot be cloned). */
+  clone(): FetchSyncResponse
+  /** Returns the parsed form (for the `multipart/form-data` and `application/x-www-form-urlencoded` bodies). */
+  formData(): FormData
   /** Returns a result of parsing the response body text as JSON. */
   json(): unknown
-  /** Returns a text representation of the response body */
+  /** Returns a text representation of the response body (decoded using the response charset, UTF-8 by default) */
   text(): string
 }
 
@@ -89,10 +370,302 @@ declare global {
   /**
    * Send an HTTP request (synchronously).
    *
+   * Network failures are thrown as a `TypeError` with the `code` property (`ECONNREFUSED`, `ECONNRESET`, `ENOTFOUND`,
+   * `ETIMEDOUT`, `ENETUNREACH`, `ECANCELED`, `ETLS`, `EREDIRECT`, `EINVALIDURL`, `ENOTRECORDED`, `ETOOLARGE` or
+   * `EUNKNOWN`) and the `cause`. The `ENOTRECORDED` code means that there is no recorded response for the request in
+   * the strict replay mode (`--replay <dir> --replay-strict`), `ETOOLARGE` - that the response body exceeds the
+   * `maxBodySize` option.
+   *
+   * @example
+   * try {
+   *   fetchSync('http://127.0.0.1:1')
+   * } catch (e) {
+   *   assert.equals(e.code, 'ECONNREFUSED')
+   * }
+   *
    * @external go Implemented on the Golang side
    */
+  function fetchSync(url: string, options: FetchSyncOptions & { stream: true }): FetchSyncStreamResponse
   function fetchSync(url: string, options?: FetchSyncOptions): FetchSyncResponse
 
+  /**
+   * WHATWG-style HTTP headers (names are case-insensitive).
+   *
+   * @example
+   * const resp = fetchSync('https://example.com')
+   * resp.headers.get('content-type')
+   * resp.headers.getSetCookie() // ['a=1', 'b=2']
+   *
+   * @external go Implemented on the Golang side
+   */
+  class Headers {
+    constructor(init?: Headers | Record<string, string | number | (string | number)[]> | [string, string][])
+    append(name: string, value: string): void
+    delete(name: string): void
+    /** Returns all the values joined with ", " (`null` if there is no such header). */
+    get(name: string): string | null
+    /** Returns all the values separately. */
+    getAll(name: string): string[]
+    /** Returns the `Set-Cookie` header values. */
+    getSetCookie(): string[]
+    has(name: string): boolean
+    set(name: string, value: string): void
+    /**
+     * Lowercased names with the values, sorted by name (the values are joined with ", ", except the `Set-Cookie`
+     * ones, that are returned as the separate entries).
+     */
+    entries(): IterableIterator<[string, string]>
+    keys(): IterableIterator<string>
+    values(): IterableIterator<string>
+    forEach(fn: (value: string, name: string) => void): void
+    [Symbol.iterator](): IterableIterator<[string, string]>
+  }
+
+  /**
+   * A set of the form fields, sent as the `multipart/form-data` body (the content type with the boundary is set
+   * automatically).
+   *
+   * @example
+   * const form = new FormData()
+   * form.append('name', 'foo')
+   * form.appendFile('avatar', './avatar.png')
+   * fetchSync('https://example.com/upload', {method: 'POST', body: form})
+   *
+   * @external go Implemented on the Golang side
+   */
+  class FormData {
+    /** Append the field. Binary values are appended as the file parts (with the optional file name). */
+    append(name: string, value: string | ArrayBuffer | ArrayBufferView | FormFile, filename?: string): void
+    /** Append the file part, read from the disk. */
+    appendFile(name: string, path: string, options?: {filename?: string, type?: string}): void
+    /** Replace the field value (all the fields with the same name are removed). */
+    set(name: string, value: string | ArrayBuffer | ArrayBufferView | FormFile, filename?: string): void
+    delete(name: string): void
+    get(name: string): string | FormFile | null
+    getAll(name: string): (string | FormFile)[]
+    has(name: string): boolean
+    entries(): IterableIterator<[string, string | FormFile]>
+    keys(): IterableIterator<string>
+    values(): IterableIterator<string | FormFile>
+    forEach(fn: (value: string | FormFile, name: string) => void): void
+    [Symbol.iterator](): IterableIterator<[string, string | FormFile]>
+  }
+
+  /**
+   * A set of the query parameters, sent as the `application/x-www-form-urlencoded` body.
+   *
+   * @external go Implemented on the Golang side
+   */
+  class URLSearchParams {
+    constructor(init?: string | Record<string, string> | [string, string][])
+    append(name: string, value: string): void
+    set(name: string, value: string): void
+    delete(name: string): void
+    get(name: string): string | null
+    getAll(name: string): string[]
+    has(name: string): boolean
+    sort(): void
+    /** Returns the query string (without the leading `?`). */
+    toString(): string
+    entries(): IterableIterator<[string, string]>
+    keys(): IterableIterator<string>
+    values(): IterableIterator<string>
+    forEach(fn: (value: string, name: string) => void): void
+    [Symbol.iterator](): IterableIterator<[string, string]>
+  }
+
+  /**
+   * Allows to abort the HTTP requests. There is no asynchronous `fetch` (the runtime has no event loop to deliver
+   * the promises to), so the `abort()` call affects the requests sent after it and the streamed bodies that are still
+   * being read, but not a pending `fetchSync` call.
+   *
+   * @example
+   * const controller = new AbortController()
+   * const resp = fetchSync('https://example.com/events', {stream: true, signal: controller.signal})
+   * resp.body.readLine()
+   * controller.abort()
+   * resp.body.readLine() // throws the AbortError
+   * fetchSync('https://example.com', {signal: controller.signal}) // throws the AbortError, nothing is sent
+   *
+   * @external go Implemented on the Golang side
+   */
+  class AbortController {
+    /** The signal to pass to the request options. */
+    readonly signal: AbortSignal
+    /** Abort the signal (the `AbortError` is used as the reason by default). */
+    abort(reason?: unknown): void
+  }
+
+  /**
+   * The abort signal factory.
+   *
+   * @external go Implemented on the Golang side
+   */
+  const AbortSignal: {
+    /** Returns the signal that will be aborted (with the `TimeoutError` reason) after the given milliseconds. */
+    timeout(ms: number): AbortSignal
+    /** Returns the already aborted signal. */
+    abort(reason?: unknown): AbortSignal
+  }
+
+  /**
+   * HTTP requests settings.
+   *
+   * @external go Implemented on the Golang side
+   */
+  const http: {
+    /**
+     * Update the default options for all the HTTP requests (they can be also set using the `--base-url`, `--header`
+     * and `--query` flags). The base URL is replaced, headers and query parameters are merged (`null` removes the
+     * default value). Current defaults are returned.
+     *
+     * @example
+     * http.defaults({baseURL: 'https://staging.example.com/api', headers: {Authorization: 'Bearer ...'}})
+     * fetchSync('/users') // https://staging.example.com/api/users
+     */
+    defaults(options?: {
+      /** Relative request URLs are appended to it. */
+      baseURL?: string | null
+      headers?: Record<string, string | null>
+      query?: Record<string, string | number | boolean | (string | number | boolean)[] | null>
+    }): {baseURL: string, headers: Record<string, string>, query: Record<string, string | string[]>}
+    /**
+     * Create the OAuth2 client (client credentials or password grant). Tokens are cached per script (the same
+     * options share them) and refreshed on expiry. The client can be used as the `auth` option of the request.
+     *
+     * @example
+     * const auth = http.oauth2({tokenURL: 'https://auth.example.com/token', clientId: 'id', clientSecret: 'secret'})
+     * fetchSync('https://api.example.com/users', {auth})
+     */
+    oauth2(options: OAuth2Options): OAuth2Client
+  }
+
+  /**
+   * The cookie jar, used by the HTTP requests (cookies are stored and sent automatically).
+   *
+   * @external go Implemented on the Golang side
+   */
+  const cookies: {
+    /** Returns the cookies that will be sent with the request to the URL (or domain). */
+    get(urlOrDomain: string): {name: string, value: string}[]
+    /** Set the cookie for the URL (or domain). */
+    set(urlOrDomain: string, name: string, value: string, options?: {
+      path?: string
+      domain?: string
+      /** Expiration date. */
+      expires?: Date | number
+      /** Max age in seconds. */
+      maxAge?: number
+      secure?: boolean
+      httpOnly?: boolean
+    }): void
+    /** Remove the cookies of the domain (including its subdomains), or all the cookies if the domain is omitted. */
+    clear(urlOrDomain?: string): void
+  }
+
+  /**
+   * Server-sent events client (like the browser `EventSource`). The events are received in the background, and the
+   * script receives them using the `read()`, `listen()` or `events()` methods (the listeners are called for the
+   * received events at the same time). The connection is re-established with the `Last-Event-ID` header when the
+   * stream is ended, and closed when the script execution is finished.
+   *
+   * @example
+   * const es = new EventSource('https://example.com/updates', {timeout: 5000})
+   *
+   * for (const e of es) { // stops after 5 seconds without the events
+   *   console.log(e.event, e.data)
+   * }
+   *
+   * @external go Implemented on the Golang side
+   */
+  class EventSource implements Iterable<EventSourceEvent> {
+    static readonly CONNECTING: 0
+    static readonly OPEN: 1
+    static readonly CLOSED: 2
+
+    constructor(url: string, options?: EventSourceOptions)
+
+    /** The resolved URL. */
+    readonly url: string
+    /** The connection state (updated when the events are received). */
+    readonly readyState: 0 | 1 | 2
+    /** The last received event ID. */
+    readonly lastEventId: string
+
+    onopen: ((event: { readonly event: 'open' }) => void) | null
+    onmessage: ((event: EventSourceEvent) => void) | null
+    onerror: ((event: EventSourceErrorEvent) => void) | null
+
+    /** Adds the listener for the event type (`open`, `error`, `message` or any custom event name). */
+    addEventListener(type: string, listener: (event: EventSourceEvent) => void): void
+    /** Removes the listener, added using `addEventListener()`. */
+    removeEventListener(type: string, listener: (event: EventSourceEvent) => void): void
+    /** Waits for the next event, returns `null` on timeout (in milliseconds) or when the connection is closed. */
+    read(timeout?: number): EventSourceEvent | null
+    /**
+     * Receives the events (and calls the listeners) until the timeout (in milliseconds) is exceeded or the connection
+     * is closed. Returns the number of the received events.
+     */
+    listen(timeout?: number): number
+    /** Returns the iterator over the events, each one is waited up to the timeout (in milliseconds). */
+    events(timeout?: number): IterableIterator<EventSourceEvent>
+    /** Closes the connection (no reconnection is made). */
+    close(): void
+
+    [Symbol.iterator](): Iterator<EventSourceEvent>
+  }
+
+  /**
+   * WebSocket client. The connection is established synchronously in the constructor (the network error is thrown on
+   * failure, like `fetchSync` does), the received messages are queued until they are read using `receive()` or
+   * `messages()`. The connection is closed when the script execution is finished (or the execution time is exceeded).
+   *
+   * @example
+   * const ws = new WebSocket('wss://example.com/chat', {protocols: ['chat.v1'], headers: {'X-Token': 'xxx'}})
+   *
+   * ws.send(JSON.stringify({text: 'hello'}))
+   * assert.equals(JSON.parse(ws.receive(1000).data).status, 'delivered')
+   * ws.close()
+   *
+   * @external go Implemented on the Golang side
+   */
+  class WebSocket implements Iterable<WebSocketMessage> {
+    static readonly CONNECTING: 0
+    static readonly OPEN: 1
+    static readonly CLOSING: 2
+    static readonly CLOSED: 3
+
+    /** The `http(s)://` URLs are also accepted (e.g. relative to the `http.defaults()` base URL). */
+    constructor(url: string, protocolsOrOptions?: string | string[] | WebSocketOptions)
+
+    /** The resolved URL. */
+    readonly url: string
+    /** The negotiated subprotocol (empty if none). */
+    readonly protocol: string
+    /** The connection state. */
+    readonly readyState: 0 | 1 | 2 | 3
+    /** The close code (`0` while the connection is open, `1006` if it is closed without the close frame). */
+    readonly closeCode: number
+    /** The close reason, sent by the server. */
+    readonly closeReason: string
+
+    /** Sends the text (string) or binary (ArrayBuffer or its view) message. */
+    send(data: string | ArrayBuffer | ArrayBufferView): void
+    /** Waits for the next message, returns `null` on timeout (in milliseconds) or when the connection is closed. */
+    receive(timeout?: number): WebSocketMessage | null
+    /** Returns the iterator over the messages, each one is waited up to the timeout (in milliseconds). */
+    messages(timeout?: number): IterableIterator<WebSocketMessage>
+    /**
+     * Sends the ping and waits for the pong (the `TypeError` with the `ETIMEDOUT` code is thrown on timeout).
+     * Returns the round-trip time in milliseconds.
+     */
+    ping(data?: string, timeout?: number): number
+    /** Closes the connection with the code (`1000` or `3000-4999`, `1000` by default) and the reason. */
+    close(code?: number, reason?: string): void
+
+    [Symbol.iterator](): Iterator<WebSocketMessage>
+  }
+
   /** Send HTTP request by GET method. */
   function get(url: string, options?: FetchSyncOptions): FetchSyncResponse
   /** Send HTTP request by POST method. */
@@ -40,27 +40,90 @@ const tests = new class {
     }
   }
 
+  /**
+   * Walks the registered describe blocks and tests in the execution order: the describe blocks are executed first
+   * (they register the nested describe blocks and tests), then the registered tests are passed to the `onTests`,
+   * and then the nested describe blocks are walked in the same way.
+   *
+   * @param {function(function, string)} onDescribe must execute the describe block function
+   * @param {function(Map<string, Function>)} onTests must consume the tests queue
+   */
+  walk(onDescribe, onTests) {
+    this.reduceMap(this.describeQueue, onDescribe)
+
+    onTests(this.testsQueue)
+
+    if (this.describeQueue.size > 0) {
+      this.walk(onDescribe, onTests) // recursive walk
+    }
+  }
+
   /** Run all the tests. */
   run() {
-    this.reduceMap(this.describeQueue, (fn) => fn())
+    this.walk((fn) => fn(), (testsQueue) => {
+      if (testsQueue.size > 0) { // run tests
+        this.reduceMap(this.beforeAll, (fn) => fn())
 
-    if (this.testsQueue.size > 0) { // run tests
-      this.reduceMap(this.beforeAll, (fn) => fn())
+        this.reduceMap(testsQueue, (fn, name) => {
+          this.beforeEach.forEach((fn) => fn(name))
 
-      this.reduceMap(this.testsQueue, (fn, name) => {
-        this.beforeEach.forEach((fn) => fn(name))
+          fn()
 
-        fn()
+          this.afterEach.forEach((fn) => fn(name))
+        })
 
-        this.afterEach.forEach((fn) => fn(name))
-      })
+        this.reduceMap(this.afterAll, (fn) => fn())
+      }
+    })
+  }
 
-      this.reduceMap(this.afterAll, (fn) => fn())
+  /**
+   * Collect the tests tree without running the tests (describe blocks are executed to register nested tests). The
+   * same walk as run() is used, so the tests are listed in the execution order (within each describe block).
+   *
+   * @return {{type: 'test'|'describe', name: string, children?: Array}[]}
+   */
+  collect() {
+    const root = []
+    /** @type {Map<string, Array>} the nodes list of the describe block, where the test was registered */
+    const testOwners = new Map()
+    /** @type {Map<string, Array>} the nodes list of the describe block, where the describe block was registered */
+    const describeOwners = new Map()
+    /** @type {[Array, Object][]} the describe nodes of the current level (added after the level tests) */
+    let pending = []
+
+    /**
+     * @param {Map<string, *>} queue
+     * @return {function(Map<string, Array>, Array)} assigns the owner to the newly registered queue items
+     */
+    const track = (queue) => {
+      const before = new Set(queue.keys())
+
+      return (owners, nodes) => queue.forEach((_, name) => before.has(name) || owners.set(name, nodes))
     }
 
-    if (this.describeQueue.size > 0) {
-      this.run() // recursive run
-    }
+    this.walk((fn, name) => {
+      const node = {type: 'describe', name: name, children: []}
+      const [trackTests, trackDescribes] = [track(this.testsQueue), track(this.describeQueue)]
+
+      pending.push([describeOwners.get(name) || root, node])
+      describeOwners.delete(name)
+
+      fn()
+
+      trackTests(testOwners, node.children)
+      trackDescribes(describeOwners, node.children)
+    }, (testsQueue) => {
+      this.reduceMap(testsQueue, (_, name) => {
+        (testOwners.get(name) || root).push({type: 'test', name: name})
+        testOwners.delete(name)
+      })
+
+      pending.forEach(([nodes, node]) => nodes.push(node))
+      pending = []
+    })
+
+    return root
   }
 
   /**
@@ -3,10 +3,18 @@ package js
 import (
 	"context"
 	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	js "github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
 	"github.com/dop251/goja/parser"
 	"github.com/pkg/errors"
 
@@ -34,9 +42,22 @@ type (
 		events  chan events.Event
 		printer printer.Printer
 
+		network      *disabledNetwork // nil if the network access is allowed
+		fetchOptions []addons.FetchOption
+
+		lexicalNamesMu sync.Mutex
+		lexicalNames   map[string]struct{} // top-level lexical declarations (they are not global object properties)
+
 		closeOnce sync.Once
 	}
 
+	// TestNode is a node of the tests tree - a test or a describe block with nested nodes.
+	TestNode struct {
+		Type     string     `json:"type"` // "test" or "describe"
+		Name     string     `json:"name"`
+		Children []TestNode `json:"children,omitempty"`
+	}
+
 	// addonRegisterer is an interface for all addons.
 	addonRegisterer interface {
 		Register(*js.Runtime) error
@@ -48,6 +69,50 @@ func WithPrinter(p printer.Printer) RuntimeOption {
 	return func(r *Runtime) { r.printer = p }
 }
 
+// WithFetchOptions sets up the options for the HTTP requests sending.
+func WithFetchOptions(options ...addons.FetchOption) RuntimeOption {
+	return func(r *Runtime) { r.fetchOptions = append(r.fetchOptions, options...) }
+}
+
+// WithoutNetwork disables the network access for the runtime (the HTTP requests are not sent, the stub responses
+// are returned instead, and the WebSocket connections fail).
+func WithoutNetwork() RuntimeOption {
+	return func(r *Runtime) { r.network = new(disabledNetwork) }
+}
+
+// errNetworkDisabled is returned when the network connection is requested with the disabled network access.
+var errNetworkDisabled = errors.New("network access is disabled")
+
+// disabledNetwork is an HTTP transport that doesn't send any requests - the "503 Service Unavailable" stub
+// response is returned instead, so the scripts with the top-level requests can be evaluated (e.g. to collect the
+// tests). It remembers whether the network access was requested.
+type disabledNetwork struct{ used atomic.Bool }
+
+func (n *disabledNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	n.used.Store(true)
+
+	var body = errNetworkDisabled.Error()
+
+	return &http.Response{
+		Status:        "503 Service Unavailable",
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// DialContext refuses to establish the network connections (it is used for the WebSocket connections).
+func (n *disabledNetwork) DialContext(context.Context, string, string) (net.Conn, error) {
+	n.used.Store(true)
+
+	return nil, errNetworkDisabled
+}
+
 // NewRuntime creates new Runtime instance. Don't forget to close it after usage.
 func NewRuntime(ctx context.Context, log log.Logger, options ...RuntimeOption) (*Runtime, error) {
 	var r = &Runtime{ // defaults
@@ -63,11 +128,21 @@ func NewRuntime(ctx context.Context, log log.Logger, options ...RuntimeOption) (
 		opt(r)
 	}
 
+	if r.network != nil {
+		r.fetchOptions = append(r.fetchOptions, addons.WithFetchTransport(r.network))
+	}
+
+	var fetch = addons.NewFetch(ctx, append([]addons.FetchOption{addons.WithFetchLogger(log)}, r.fetchOptions...)...)
+
 	for _, addon := range []addonRegisterer{
 		addons.NewIO(r.runtime, os.Stdout, os.Stderr, r.printer),
 		addons.NewConsole(r.runtime, log),
 		addons.NewProcess(ctx, r.runtime),
-		addons.NewFetch(ctx, nil),
+		fetch,
+		addons.NewEventSource(ctx, fetch),
+		addons.NewWebSocket(ctx, fetch),
+		addons.NewAbort(ctx, r.runtime),
+		addons.NewForms(r.runtime),
 		addons.NewEvents(ctx, r.runtime, r.events),
 		addons.NewFaker(r.runtime),
 		addons.NewEncoding(r.runtime),
@@ -80,15 +155,57 @@ func NewRuntime(ctx context.Context, log log.Logger, options ...RuntimeOption) (
 		}
 	}
 
-	if _, err := r.runtime.RunScript("global.js", global); err != nil {
+	globalAst, parsingErr := parser.ParseFile(nil, "global.js", global, 0)
+	if parsingErr != nil {
+		r.Close()
+
+		return nil, parsingErr
+	}
+
+	globalProgram, compileErr := js.CompileAST(globalAst, false)
+	if compileErr != nil {
+		r.Close()
+
+		return nil, compileErr
+	}
+
+	if _, err := r.runtime.RunProgram(globalProgram); err != nil {
 		r.Close()
 
 		return nil, err
 	}
 
+	r.rememberLexicalNames(globalAst)
+
 	return r, nil
 }
 
+// rememberLexicalNames stores the names of top-level lexical declarations (`const`, `let`, `class`) of the program.
+func (r *Runtime) rememberLexicalNames(program *ast.Program) {
+	r.lexicalNamesMu.Lock()
+	defer r.lexicalNamesMu.Unlock()
+
+	if r.lexicalNames == nil {
+		r.lexicalNames = make(map[string]struct{})
+	}
+
+	for _, stmt := range program.Body {
+		switch s := stmt.(type) {
+		case *ast.LexicalDeclaration:
+			for _, binding := range s.List {
+				if id, ok := binding.Target.(*ast.Identifier); ok {
+					r.lexicalNames[id.Name.String()] = struct{}{}
+				}
+			}
+
+		case *ast.ClassDeclaration:
+			if s.Class != nil && s.Class.Name != nil {
+				r.lexicalNames[s.Class.Name.Name.String()] = struct{}{}
+			}
+		}
+	}
+}
+
 // Events returns channel with events. Channel reading is required for the events working.
 func (r *Runtime) Events() <-chan events.Event { return r.events }
 
@@ -107,6 +224,160 @@ func (r *Runtime) RunScript(name, script string) error {
 	return nil
 }
 
+// ErrTestsIncomplete is returned by the CollectTests (with the tests, registered before the failure) when the script
+// evaluation failed after the stub network response was used, so the tests list may be incomplete.
+var ErrTestsIncomplete = errors.New("the tests list may be incomplete")
+
+// CollectTests runs the JS script without running the registered tests, and returns the tests tree. When the
+// network access is disabled and the script has failed after using it (e.g. on the stub response assertion), the
+// tests registered before the failure are returned with the ErrTestsIncomplete error.
+func (r *Runtime) CollectTests(name, script string) ([]TestNode, error) {
+	var incompleteErr error
+
+	if _, err := r.runtime.RunScript(name, script); err != nil {
+		var (
+			exception   *js.Exception
+			interrupted *js.InterruptedError
+		)
+
+		if r.network == nil || !r.network.used.Load() || !(errors.As(err, &exception) || errors.As(err, &interrupted)) {
+			return nil, err
+		}
+
+		r.runtime.ClearInterrupt() // the script may be interrupted by the `mustBe` assertion
+
+		incompleteErr = fmt.Errorf("%w (the script failed after the stub network response was used: %s)",
+			ErrTestsIncomplete, err,
+		)
+	}
+
+	var tests = r.runtime.Get("tests").ToObject(r.runtime)
+
+	collect, ok := js.AssertFunction(tests.Get("collect"))
+	if !ok {
+		return nil, errors.New("tests collecting function not found")
+	}
+
+	result, err := collect(tests)
+	if err != nil {
+		return nil, errors.Wrap(err, "tests collecting failed")
+	}
+
+	var nodes = make([]TestNode, 0)
+
+	if err = r.runtime.ExportTo(result, &nodes); err != nil {
+		return nil, err
+	}
+
+	return nodes, incompleteErr
+}
+
+// ErrIncompleteInput is returned when the evaluated code is incomplete (e.g. the block is not closed yet).
+var ErrIncompleteInput = errors.New("incomplete input")
+
+// Eval evaluates the JS code (without running the registered tests) and returns the result as a string, formatted
+// in the same way as the console methods do.
+func (r *Runtime) Eval(name, code string) (string, error) {
+	program, parsingErr := parser.ParseFile(nil, name, code, 0)
+	if parsingErr != nil {
+		if strings.Contains(parsingErr.Error(), "Unexpected end of input") {
+			return "", ErrIncompleteInput
+		}
+
+		return "", parsingErr
+	}
+
+	compiled, compileErr := js.CompileAST(program, false)
+	if compileErr != nil {
+		return "", compileErr
+	}
+
+	r.runtime.ClearInterrupt() // the previous evaluation may be interrupted
+
+	value, runErr := r.runtime.RunProgram(compiled)
+	if runErr != nil {
+		return "", runErr
+	}
+
+	r.rememberLexicalNames(program)
+
+	return addons.Stringify(value), nil
+}
+
+// Completions returns the sorted property names of the object located by the path (like `faker` or `foo.bar`).
+// Global names are returned for the empty path.
+func (r *Runtime) Completions(path string) []string {
+	var (
+		names = make(map[string]struct{})
+		obj   *js.Object
+	)
+
+	if path == "" {
+		obj = r.runtime.GlobalObject()
+
+		r.lexicalNamesMu.Lock()
+		for name := range r.lexicalNames {
+			names[name] = struct{}{}
+		}
+		r.lexicalNamesMu.Unlock()
+	} else {
+		for i, segment := range strings.Split(path, ".") {
+			var value js.Value
+
+			if i == 0 {
+				value = r.runtime.Get(segment)
+			} else {
+				value = obj.Get(segment)
+			}
+
+			if value == nil || js.IsUndefined(value) || js.IsNull(value) {
+				return []string{}
+			}
+
+			obj = value.ToObject(r.runtime)
+		}
+	}
+
+	var (
+		object                 = r.runtime.Get("Object").ToObject(r.runtime)
+		objectProto            = object.Get("prototype").ToObject(r.runtime)
+		getOwnPropertyNames, _ = js.AssertFunction(object.Get("getOwnPropertyNames"))
+	)
+
+	for proto := obj; proto != nil; proto = proto.Prototype() {
+		if proto == objectProto && path != "" {
+			break // skip the common object methods
+		}
+
+		list, err := getOwnPropertyNames(js.Undefined(), proto)
+		if err != nil {
+			break
+		}
+
+		var keys []string
+
+		if err = r.runtime.ExportTo(list, &keys); err != nil {
+			break
+		}
+
+		for _, key := range keys {
+			if key != "constructor" {
+				names[key] = struct{}{}
+			}
+		}
+	}
+
+	var result = make([]string, 0, len(names))
+
+	for name := range names {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
 // Interrupt interrupts the runtime.
 func (r *Runtime) Interrupt(reason string) {
 	r.runtime.Interrupt(reason)
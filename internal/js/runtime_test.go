@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tarampampam/poke/internal/js"
 	"github.com/tarampampam/poke/internal/log"
@@ -145,3 +146,222 @@ func TestRuntime_AssertEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestRuntime_CollectTests(t *testing.T) {
+	runtime, err := js.NewRuntime(context.Background(), log.NewNop(), js.WithoutNetwork())
+	assert.NoError(t, err)
+
+	defer runtime.Close()
+
+	tree, err := runtime.CollectTests("", `
+test('foo', () => { throw new Error('must not be executed') })
+
+describe('bar', () => {
+  it('baz', () => { throw new Error('must not be executed') })
+
+  describe('qux', () => {
+    test('quux', () => {})
+  })
+})`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []js.TestNode{
+		{Type: "test", Name: "foo"},
+		{Type: "describe", Name: "bar", Children: []js.TestNode{
+			{Type: "test", Name: "baz"},
+			{Type: "describe", Name: "qux", Children: []js.TestNode{
+				{Type: "test", Name: "quux"},
+			}},
+		}},
+	}, tree)
+}
+
+func TestRuntime_CollectTestsRunOrder(t *testing.T) {
+	const script = `
+const executed = []
+
+describe('a', () => {
+  test('a1', () => { executed.push('a1') })
+
+  describe('a2', () => {
+    test('a2x', () => { executed.push('a2x') })
+  })
+})
+
+test('top', () => { executed.push('top') })
+
+describe('b', () => {
+  test('b1', () => { executed.push('b1') })
+})`
+
+	runtime, err := js.NewRuntime(context.Background(), log.NewNop(), js.WithoutNetwork())
+	require.NoError(t, err)
+
+	defer runtime.Close()
+
+	tree, err := runtime.CollectTests("", script)
+	require.NoError(t, err)
+
+	assert.Equal(t, []js.TestNode{ // the same order, as the tests are executed
+		{Type: "test", Name: "top"},
+		{Type: "describe", Name: "a", Children: []js.TestNode{
+			{Type: "test", Name: "a1"},
+			{Type: "describe", Name: "a2", Children: []js.TestNode{
+				{Type: "test", Name: "a2x"},
+			}},
+		}},
+		{Type: "describe", Name: "b", Children: []js.TestNode{
+			{Type: "test", Name: "b1"},
+		}},
+	}, tree)
+
+	executing, err := js.NewRuntime(context.Background(), log.NewNop(), js.WithoutNetwork())
+	require.NoError(t, err)
+
+	defer executing.Close()
+
+	require.NoError(t, executing.RunScript("", script))
+	assert.NoError(t, executing.RunScript("", `mustBe.equals(executed.join(), 'top,a1,b1,a2x')`))
+}
+
+func TestRuntime_WithoutNetwork(t *testing.T) {
+	runtime, err := js.NewRuntime(context.Background(), log.NewNop(), js.WithoutNetwork())
+	assert.NoError(t, err)
+
+	defer runtime.Close()
+
+	assert.NoError(t, runtime.RunScript("", `
+const resp = get('http://127.0.0.1:1/')
+
+mustBe.false(resp.ok)
+mustBe.contains('network access is disabled', resp.body)
+
+const source = new EventSource('http://127.0.0.1:1/')
+mustBe.true(source.read(100) === null)
+mustBe.equals(source.readyState, EventSource.CLOSED)
+
+try {
+  new WebSocket('ws://127.0.0.1:1/')
+  throw new Error('must fail')
+} catch (e) {
+  mustBe.contains('network access is disabled', e.message)
+}`))
+}
+
+func TestRuntime_CollectTestsWithoutNetwork(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveScript     string
+		wantTree       []js.TestNode
+		wantIncomplete bool
+		wantError      string
+	}{
+		"top-level request": {
+			giveScript: `const resp = get('https://example.com/'); test('foo', () => {})`,
+			wantTree:   []js.TestNode{{Type: "test", Name: "foo"}},
+		},
+		"failed assertion on the stub response": {
+			giveScript: `
+const resp = get('https://example.com/')
+
+test('foo', () => {})
+
+assert.equals(resp.json().foo, 'bar')
+
+test('bar', () => {})`,
+			wantTree:       []js.TestNode{{Type: "test", Name: "foo"}},
+			wantIncomplete: true,
+		},
+		"interrupting assertion on the stub response": {
+			giveScript: `
+test('foo', () => {})
+
+mustBe.equals(get('https://example.com/').status, 200)
+
+test('bar', () => {})`,
+			wantTree:       []js.TestNode{{Type: "test", Name: "foo"}},
+			wantIncomplete: true,
+		},
+		"error without the network usage": {
+			giveScript: `test('foo', () => {}); throw new Error('baz')`,
+			wantError:  "baz",
+		},
+	} {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			runtime, err := js.NewRuntime(context.Background(), log.NewNop(), js.WithoutNetwork())
+			require.NoError(t, err)
+
+			defer runtime.Close()
+
+			tree, err := runtime.CollectTests("", tt.giveScript)
+
+			switch {
+			case tt.wantError != "":
+				assert.ErrorContains(t, err, tt.wantError)
+				assert.NotErrorIs(t, err, js.ErrTestsIncomplete)
+
+				return
+
+			case tt.wantIncomplete:
+				assert.ErrorIs(t, err, js.ErrTestsIncomplete)
+
+			default:
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantTree, tree)
+		})
+	}
+}
+
+func TestRuntime_Eval(t *testing.T) {
+	runtime, err := js.NewRuntime(context.Background(), log.NewNop())
+	assert.NoError(t, err)
+
+	defer runtime.Close()
+
+	result, err := runtime.Eval("", "const foo = {bar: 1}; foo")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"bar":1}`, result)
+
+	result, err = runtime.Eval("", "foo.bar + 1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", result)
+
+	result, err = runtime.Eval("", "undefined")
+	assert.NoError(t, err)
+	assert.Equal(t, "undefined", result)
+
+	_, err = runtime.Eval("", "if (true) {")
+	assert.ErrorIs(t, err, js.ErrIncompleteInput)
+
+	_, err = runtime.Eval("", "throw new Error('baz')")
+	assert.ErrorContains(t, err, "baz")
+
+	_, err = runtime.Eval("", "test('must not be executed', () => { throw new Error('executed') })")
+	assert.NoError(t, err)
+}
+
+func TestRuntime_Completions(t *testing.T) {
+	runtime, err := js.NewRuntime(context.Background(), log.NewNop())
+	assert.NoError(t, err)
+
+	defer runtime.Close()
+
+	_, err = runtime.Eval("", "const myVariable = {foo: 1, bar: {baz: 2}}")
+	assert.NoError(t, err)
+
+	var globals = runtime.Completions("")
+
+	for _, name := range []string{"myVariable", "faker", "fetchSync", "get", "assert", "describe", "JSON", "Math"} {
+		assert.Contains(t, globals, name)
+	}
+
+	assert.Equal(t, []string{"bar", "foo"}, runtime.Completions("myVariable"))
+	assert.Equal(t, []string{"baz"}, runtime.Completions("myVariable.bar"))
+	assert.Contains(t, runtime.Completions("faker"), "uuid")
+	assert.Contains(t, runtime.Completions("assert"), "equals")
+	assert.Empty(t, runtime.Completions("unknownVariable"))
+	assert.Empty(t, runtime.Completions("myVariable.unknown"))
+}
@@ -0,0 +1,34 @@
+// Package redact contains the rules of the sensitive data redaction, used when the HTTP traffic is logged or written
+// into the files (e.g. the debug logs or HAR files).
+package redact
+
+import "net/http"
+
+// Placeholder replaces the sensitive values.
+const Placeholder = "[REDACTED]"
+
+// sensitiveHeaders are the headers with sensitive values (in the canonical form).
+var sensitiveHeaders = map[string]struct{}{ //nolint:gochecknoglobals
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+	"X-Api-Key":           {},
+	"X-Auth-Token":        {},
+}
+
+// IsSensitiveHeader checks whether the header (the name is case-insensitive) value is sensitive.
+func IsSensitiveHeader(name string) bool {
+	_, ok := sensitiveHeaders[http.CanonicalHeaderKey(name)]
+
+	return ok
+}
+
+// HeaderValue returns the header value, or the Placeholder if the header value is sensitive.
+func HeaderValue(name, value string) string {
+	if IsSensitiveHeader(name) {
+		return Placeholder
+	}
+
+	return value
+}
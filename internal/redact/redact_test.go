@@ -0,0 +1,25 @@
+package redact_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tarampampam/poke/internal/redact"
+)
+
+func TestHeaderValue(t *testing.T) {
+	for name, want := range map[string]string{
+		"Authorization":       redact.Placeholder,
+		"proxy-authorization": redact.Placeholder,
+		"COOKIE":              redact.Placeholder,
+		"Set-Cookie":          redact.Placeholder,
+		"x-api-key":           redact.Placeholder,
+		"X-Auth-Token":        redact.Placeholder,
+		"Content-Type":        "value",
+		"X-Foo":               "value",
+	} {
+		assert.Equal(t, want, redact.HeaderValue(name, "value"), name)
+		assert.Equal(t, want == redact.Placeholder, redact.IsSensitiveHeader(name), name)
+	}
+}